@@ -0,0 +1,87 @@
+package accounts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Directory kinds selectable with CODEVIDEO_USER_DIRECTORY.
+const (
+	KindClerk = "clerk"
+	KindFile  = "file"
+	KindNone  = "none"
+)
+
+var (
+	// ErrNotFound is returned when the directory has no such user.
+	ErrNotFound = errors.New("user not found")
+	// ErrNoEmail is returned when the user has no email address to notify.
+	ErrNoEmail = errors.New("user has no email address")
+)
+
+// Credits are a user's token fields. ReservedTokens are held by jobs that are
+// still rendering; ChargedTokens is the total ever charged for jobs, which
+// the usage ledger reconciles against.
+type Credits struct {
+	Tokens         int `json:"tokens"`
+	ReservedTokens int `json:"reservedTokens"`
+	ChargedTokens  int `json:"chargedTokens"`
+}
+
+// Account is the billing state of a user: their token credits and plan.
+type Account struct {
+	Credits
+	Unlimited          bool   `json:"unlimited"`
+	SubscriptionPlan   string `json:"subscriptionPlan"`
+	SubscriptionStatus string `json:"subscriptionStatus"`
+}
+
+// AvailableTokens returns the tokens that can still be reserved.
+func (a Account) AvailableTokens() int {
+	return a.Tokens - a.ReservedTokens
+}
+
+// Paid reports whether the user's subscription is a paid one in good standing.
+func (a Account) Paid() bool {
+	if a.Unlimited {
+		return true
+	}
+	plan := strings.ToLower(a.SubscriptionPlan)
+	if plan == "" || plan == "free" {
+		return false
+	}
+	switch strings.ToLower(a.SubscriptionStatus) {
+	case "", "active", "trialing":
+		return true
+	}
+	return false
+}
+
+// UserDirectory looks up the users jobs belong to. The environment (staging
+// or production) is the one named in the job's manifest; directories that
+// don't separate environments ignore it.
+type UserDirectory interface {
+	// Email returns the address to notify, or ErrNoEmail.
+	Email(ctx context.Context, environment string, userID string) (string, error)
+	// Account returns the user's credits and plan.
+	Account(ctx context.Context, environment string, userID string) (Account, error)
+	// SetCredits stores the user's token fields.
+	SetCredits(ctx context.Context, environment string, userID string, credits Credits) error
+}
+
+// New returns the directory of the given kind; path is the users file of the
+// file-backed directory.
+func New(kind string, path string) (UserDirectory, error) {
+	switch kind {
+	case KindClerk:
+		return ClerkDirectory{}, nil
+	case KindFile:
+		return NewFileDirectory(path), nil
+	case KindNone:
+		return NoopDirectory{}, nil
+	default:
+		return nil, fmt.Errorf("unknown user directory %q (expected %s, %s or %s)", kind, KindClerk, KindFile, KindNone)
+	}
+}
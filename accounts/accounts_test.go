@@ -0,0 +1,78 @@
+package accounts
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAccountPaid(t *testing.T) {
+	cases := []struct {
+		account Account
+		want    bool
+	}{
+		{Account{}, false},
+		{Account{SubscriptionPlan: "free"}, false},
+		{Account{SubscriptionPlan: "pro", SubscriptionStatus: "active"}, true},
+		{Account{SubscriptionPlan: "pro", SubscriptionStatus: "canceled"}, false},
+		{Account{Unlimited: true}, true},
+	}
+	for _, c := range cases {
+		if got := c.account.Paid(); got != c.want {
+			t.Errorf("%+v.Paid() = %v, want %v", c.account, got, c.want)
+		}
+	}
+}
+
+func TestFileDirectory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users.json")
+	users := `{
+		"ada": {"email": "ada@example.com", "tokens": 100, "subscriptionPlan": "pro"},
+		"bob": {"tokens": 5}
+	}`
+	if err := os.WriteFile(path, []byte(users), 0644); err != nil {
+		t.Fatal(err)
+	}
+	directory := NewFileDirectory(path)
+	ctx := context.Background()
+
+	email, err := directory.Email(ctx, "production", "ada")
+	if err != nil || email != "ada@example.com" {
+		t.Fatalf("Email = %q, %v", email, err)
+	}
+	if _, err := directory.Email(ctx, "production", "bob"); !errors.Is(err, ErrNoEmail) {
+		t.Errorf("Email of a user without address: err = %v, want ErrNoEmail", err)
+	}
+	if _, err := directory.Account(ctx, "production", "carol"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Account of an unknown user: err = %v, want ErrNotFound", err)
+	}
+
+	if err := directory.SetCredits(ctx, "production", "ada", Credits{Tokens: 90, ReservedTokens: 10}); err != nil {
+		t.Fatal(err)
+	}
+	account, err := NewFileDirectory(path).Account(ctx, "production", "ada")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if account.Tokens != 90 || account.ReservedTokens != 10 || account.SubscriptionPlan != "pro" {
+		t.Errorf("account after SetCredits = %+v", account)
+	}
+}
+
+func TestNoopDirectory(t *testing.T) {
+	directory, err := New(KindNone, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := directory.Email(context.Background(), "", "user"); !errors.Is(err, ErrNoEmail) {
+		t.Errorf("Email err = %v, want ErrNoEmail", err)
+	}
+	if account, _ := directory.Account(context.Background(), "", "user"); !account.Unlimited {
+		t.Error("noop account is not unlimited")
+	}
+	if _, err := New("ldap", ""); err == nil {
+		t.Error("New accepted an unknown directory kind")
+	}
+}
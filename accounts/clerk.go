@@ -0,0 +1,102 @@
+package accounts
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/clerk/clerk-sdk-go/v2"
+	"github.com/clerk/clerk-sdk-go/v2/user"
+)
+
+// ClerkDirectory reads users from Clerk with the secret key of the job's
+// environment (CLERK_SECRET_KEY or CLERK_SECRET_KEY_STAGING). Credits and plan
+// live in the user's public metadata.
+type ClerkDirectory struct{}
+
+// Email returns the user's primary email address, or their first one.
+func (ClerkDirectory) Email(ctx context.Context, environment string, userID string) (string, error) {
+	clerkUser, err := getUser(ctx, environment, userID)
+	if err != nil {
+		return "", err
+	}
+	if len(clerkUser.EmailAddresses) == 0 {
+		return "", fmt.Errorf("%w: %s", ErrNoEmail, userID)
+	}
+	for _, address := range clerkUser.EmailAddresses {
+		if clerkUser.PrimaryEmailAddressID != nil && address.ID == *clerkUser.PrimaryEmailAddressID {
+			return address.EmailAddress, nil
+		}
+	}
+	return clerkUser.EmailAddresses[0].EmailAddress, nil
+}
+
+// Account reads the token, unlimited and subscription fields
+// of the user's public metadata.
+func (ClerkDirectory) Account(ctx context.Context, environment string, userID string) (Account, error) {
+	clerkUser, err := getUser(ctx, environment, userID)
+	if err != nil {
+		return Account{}, err
+	}
+	var account Account
+	if clerkUser.PublicMetadata != nil {
+		var meta map[string]interface{}
+		if err := json.Unmarshal(clerkUser.PublicMetadata, &meta); err == nil {
+			account.Tokens = metadataInt(meta["tokens"])
+			account.ReservedTokens = metadataInt(meta["reservedTokens"])
+			account.ChargedTokens = metadataInt(meta["chargedTokens"])
+			account.Unlimited, _ = meta["unlimited"].(bool)
+			account.SubscriptionPlan, _ = meta["subscriptionPlan"].(string)
+			account.SubscriptionStatus, _ = meta["subscriptionStatus"].(string)
+		}
+	}
+	return account, nil
+}
+
+// SetCredits updates the token fields; Clerk merges them into the rest of the metadata.
+func (ClerkDirectory) SetCredits(ctx context.Context, environment string, userID string, credits Credits) error {
+	metadata, _ := json.Marshal(credits)
+	params := user.UpdateMetadataParams{
+		PublicMetadata: (*json.RawMessage)(&metadata),
+	}
+	_, err := clerkClient(environment).UpdateMetadata(ctx, userID, &params)
+	return err
+}
+
+func getUser(ctx context.Context, environment string, userID string) (*clerk.User, error) {
+	clerkUser, err := clerkClient(environment).Get(ctx, userID)
+	if err != nil {
+		var apiErr *clerk.APIErrorResponse
+		if errors.As(err, &apiErr) && apiErr.HTTPStatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, userID)
+		}
+		return nil, err
+	}
+	return clerkUser, nil
+}
+
+func clerkClient(environment string) *user.Client {
+	apiKey := os.Getenv("CLERK_SECRET_KEY")
+	if environment == "staging" {
+		apiKey = os.Getenv("CLERK_SECRET_KEY_STAGING")
+	}
+	config := &clerk.ClientConfig{}
+	config.Key = &apiKey
+	return user.NewClient(config)
+}
+
+// metadataInt reads a number that may have been stored as a JSON number or string.
+func metadataInt(value interface{}) int {
+	switch v := value.(type) {
+	case float64:
+		return int(v)
+	case string:
+		n, _ := strconv.Atoi(v)
+		return n
+	}
+	return 0
+}
@@ -0,0 +1,122 @@
+package accounts
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// FileUser is one entry of the users file.
+type FileUser struct {
+	Email string `json:"email"`
+	Account
+}
+
+// FileDirectory keeps users in a JSON file for self-hosted deployments:
+//
+//	{"user_123": {"email": "ada@example.com", "tokens": 100, "subscriptionPlan": "pro"}}
+//
+// It serves every environment from the same file.
+type FileDirectory struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileDirectory returns a directory backed by the users file at path.
+func NewFileDirectory(path string) *FileDirectory {
+	return &FileDirectory{path: path}
+}
+
+// Email returns the user's email address.
+func (d *FileDirectory) Email(ctx context.Context, environment string, userID string) (string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	entry, err := d.lookup(userID)
+	if err != nil {
+		return "", err
+	}
+	if entry.Email == "" {
+		return "", fmt.Errorf("%w: %s", ErrNoEmail, userID)
+	}
+	return entry.Email, nil
+}
+
+// Account returns the user's credits and plan.
+func (d *FileDirectory) Account(ctx context.Context, environment string, userID string) (Account, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	entry, err := d.lookup(userID)
+	if err != nil {
+		return Account{}, err
+	}
+	return entry.Account, nil
+}
+
+// SetCredits rewrites the user's token fields.
+func (d *FileDirectory) SetCredits(ctx context.Context, environment string, userID string, credits Credits) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	users, err := d.read()
+	if err != nil {
+		return err
+	}
+	entry, ok := users[userID]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNotFound, userID)
+	}
+	entry.Credits = credits
+	users[userID] = entry
+	return d.write(users)
+}
+
+func (d *FileDirectory) lookup(userID string) (FileUser, error) {
+	users, err := d.read()
+	if err != nil {
+		return FileUser{}, err
+	}
+	entry, ok := users[userID]
+	if !ok {
+		return FileUser{}, fmt.Errorf("%w: %s", ErrNotFound, userID)
+	}
+	return entry, nil
+}
+
+func (d *FileDirectory) read() (map[string]FileUser, error) {
+	data, err := os.ReadFile(d.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]FileUser{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read users file: %w", err)
+	}
+	users := map[string]FileUser{}
+	if err := json.Unmarshal(data, &users); err != nil {
+		return nil, fmt.Errorf("failed to parse users file %s: %w", d.path, err)
+	}
+	return users, nil
+}
+
+// write replaces the file atomically so a crash never leaves it half written.
+func (d *FileDirectory) write(users map[string]FileUser) error {
+	data, err := json.MarshalIndent(users, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(d.path), ".users-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to write users file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write users file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write users file: %w", err)
+	}
+	return os.Rename(tmp.Name(), d.path)
+}
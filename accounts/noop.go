@@ -0,0 +1,25 @@
+package accounts
+
+import (
+	"context"
+	"fmt"
+)
+
+// NoopDirectory is used when there are no accounts, as in CLI usage: every
+// user has unlimited credits and no email address.
+type NoopDirectory struct{}
+
+// Email always fails with ErrNoEmail.
+func (NoopDirectory) Email(ctx context.Context, environment string, userID string) (string, error) {
+	return "", fmt.Errorf("%w: %s", ErrNoEmail, userID)
+}
+
+// Account returns an unlimited account.
+func (NoopDirectory) Account(ctx context.Context, environment string, userID string) (Account, error) {
+	return Account{Unlimited: true}, nil
+}
+
+// SetCredits does nothing.
+func (NoopDirectory) SetCredits(ctx context.Context, environment string, userID string, credits Credits) error {
+	return nil
+}
@@ -0,0 +1,121 @@
+package billing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/codevideo/codevideo-cli/accounts"
+	"github.com/codevideo/codevideo-cli/constants"
+	"github.com/codevideo/codevideo-cli/ledger"
+)
+
+// Reservation states recorded on a job's manifest.
+const (
+	StateReserved  = "reserved"
+	StateCommitted = "committed"
+	StateReleased  = "released"
+)
+
+// ErrInsufficientTokens is returned by Reserve when the user can't afford the job.
+var ErrInsufficientTokens = errors.New("insufficient tokens")
+
+// Biller reserves tokens before a job renders and commits or releases them
+// once the job's outcome is known. Balance updates are serialized so
+// concurrent jobs of the same user can't overspend, and every charge is
+// recorded in the usage ledger first.
+type Biller struct {
+	directory accounts.UserDirectory
+	ledger    *ledger.Ledger
+	mu        sync.Mutex
+}
+
+// New creates a biller keeping credits in the user directory and charges in
+// the ledger (nil for none).
+func New(directory accounts.UserDirectory, usage *ledger.Ledger) *Biller {
+	return &Biller{directory: directory, ledger: usage}
+}
+
+// Cost returns the tokens a job costs: constants.TOKENS_PER_MINUTE for every
+// started minute of its estimated length, and at least one minute's worth.
+func Cost(estimate time.Duration) int {
+	minutes := max(int(math.Ceil(estimate.Minutes())), 1)
+	return minutes * constants.TOKENS_PER_MINUTE
+}
+
+// Reserve holds cost tokens for a job and returns how many were reserved:
+// users with unlimited tokens are not charged. It fails with
+// ErrInsufficientTokens if the available balance is below cost.
+func (b *Biller) Reserve(ctx context.Context, environment string, userID string, cost int) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	account, err := b.directory.Account(ctx, environment, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read token balance: %w", err)
+	}
+	if account.Unlimited {
+		return 0, nil
+	}
+	if account.AvailableTokens() < cost {
+		return 0, fmt.Errorf("%w: the job needs %d tokens but only %d are available", ErrInsufficientTokens, cost, max(account.AvailableTokens(), 0))
+	}
+	credits := account.Credits
+	credits.ReservedTokens += cost
+	if err := b.directory.SetCredits(ctx, environment, userID, credits); err != nil {
+		return 0, fmt.Errorf("failed to reserve tokens: %w", err)
+	}
+	return cost, nil
+}
+
+// Commit spends the tokens reserved for a delivered job. The charge is
+// recorded in the ledger once per job; committing a job that was already
+// charged only drops its reservation.
+func (b *Biller) Commit(ctx context.Context, environment string, userID string, jobUUID string, reserved int) error {
+	if reserved == 0 {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	charge := true
+	if b.ledger != nil {
+		appended, err := b.ledger.Record(ledger.Entry{
+			UserID:      userID,
+			Environment: environment,
+			JobUUID:     jobUUID,
+			Delta:       -reserved,
+			Reason:      ledger.ReasonRender,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to record charge: %w", err)
+		}
+		charge = appended
+	}
+	return b.settleLocked(ctx, environment, userID, reserved, charge)
+}
+
+// Release returns reserved tokens to the user when a job fails.
+func (b *Biller) Release(ctx context.Context, environment string, userID string, reserved int) error {
+	if reserved == 0 {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.settleLocked(ctx, environment, userID, reserved, false)
+}
+
+func (b *Biller) settleLocked(ctx context.Context, environment string, userID string, reserved int, charge bool) error {
+	account, err := b.directory.Account(ctx, environment, userID)
+	if err != nil {
+		return fmt.Errorf("failed to read token balance: %w", err)
+	}
+	credits := account.Credits
+	credits.ReservedTokens = max(credits.ReservedTokens-reserved, 0)
+	if charge {
+		credits.Tokens -= reserved
+		credits.ChargedTokens += reserved
+	}
+	return b.directory.SetCredits(ctx, environment, userID, credits)
+}
@@ -0,0 +1,144 @@
+package billing
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/codevideo/codevideo-cli/accounts"
+	"github.com/codevideo/codevideo-cli/ledger"
+)
+
+// memoryStore keeps a single user's account in memory.
+type memoryStore struct {
+	accounts.NoopDirectory
+	balance accounts.Account
+}
+
+func (m *memoryStore) Account(ctx context.Context, environment string, userID string) (accounts.Account, error) {
+	return m.balance, nil
+}
+
+func (m *memoryStore) SetCredits(ctx context.Context, environment string, userID string, credits accounts.Credits) error {
+	m.balance.Credits = credits
+	return nil
+}
+
+func TestCost(t *testing.T) {
+	cases := []struct {
+		estimate time.Duration
+		want     int
+	}{
+		{0, 10},
+		{30 * time.Second, 10},
+		{time.Minute, 10},
+		{61 * time.Second, 20},
+		{12*time.Minute + 5*time.Second, 130},
+	}
+	for _, c := range cases {
+		if got := Cost(c.estimate); got != c.want {
+			t.Errorf("Cost(%s) = %d, want %d", c.estimate, got, c.want)
+		}
+	}
+}
+
+func TestReserveCommit(t *testing.T) {
+	store := &memoryStore{balance: accounts.Account{Credits: accounts.Credits{Tokens: 50}}}
+	biller := New(store, nil)
+	ctx := context.Background()
+
+	reserved, err := biller.Reserve(ctx, "production", "user", 30)
+	if err != nil || reserved != 30 {
+		t.Fatalf("Reserve = %d, %v", reserved, err)
+	}
+	// the held tokens can't be reserved by another job
+	if _, err := biller.Reserve(ctx, "production", "user", 30); !errors.Is(err, ErrInsufficientTokens) {
+		t.Fatalf("second Reserve error = %v, want ErrInsufficientTokens", err)
+	}
+	if err := biller.Commit(ctx, "production", "user", "job-1", reserved); err != nil {
+		t.Fatal(err)
+	}
+	if store.balance != (accounts.Account{Credits: accounts.Credits{Tokens: 20, ChargedTokens: 30}}) {
+		t.Errorf("balance after commit = %+v", store.balance)
+	}
+}
+
+func TestReserveRelease(t *testing.T) {
+	store := &memoryStore{balance: accounts.Account{Credits: accounts.Credits{Tokens: 50}}}
+	biller := New(store, nil)
+	ctx := context.Background()
+
+	reserved, err := biller.Reserve(ctx, "production", "user", 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := biller.Release(ctx, "production", "user", reserved); err != nil {
+		t.Fatal(err)
+	}
+	if store.balance != (accounts.Account{Credits: accounts.Credits{Tokens: 50}}) {
+		t.Errorf("balance after release = %+v", store.balance)
+	}
+}
+
+func TestReserveUnlimited(t *testing.T) {
+	store := &memoryStore{balance: accounts.Account{Unlimited: true}}
+	reserved, err := New(store, nil).Reserve(context.Background(), "production", "user", 30)
+	if err != nil || reserved != 0 {
+		t.Fatalf("Reserve = %d, %v; want 0 tokens held for an unlimited user", reserved, err)
+	}
+}
+
+func TestCommitChargesOncePerJob(t *testing.T) {
+	store := &memoryStore{balance: accounts.Account{Credits: accounts.Credits{Tokens: 100}}}
+	usage := ledger.Open(filepath.Join(t.TempDir(), "ledger.jsonl"))
+	biller := New(store, usage)
+	ctx := context.Background()
+
+	for range 2 {
+		reserved, err := biller.Reserve(ctx, "production", "user", 30)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := biller.Commit(ctx, "production", "user", "job-1", reserved); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if store.balance.Credits != (accounts.Credits{Tokens: 70, ChargedTokens: 30}) {
+		t.Errorf("credits after committing the same job twice = %+v", store.balance.Credits)
+	}
+}
+
+func TestReconcileAppliesLostCharges(t *testing.T) {
+	store := &memoryStore{balance: accounts.Account{Credits: accounts.Credits{Tokens: 100}}}
+	usage := ledger.Open(filepath.Join(t.TempDir(), "ledger.jsonl"))
+	biller := New(store, usage)
+	ctx := context.Background()
+
+	for _, job := range []string{"job-1", "job-2"} {
+		if err := biller.Commit(ctx, "production", "user", job, 20); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// a concurrent metadata update overwrote the second charge
+	store.balance.Credits = accounts.Credits{Tokens: 80, ChargedTokens: 20}
+
+	adjustments, err := Reconcile(ctx, store, usage, true)
+	if err != nil || len(adjustments) != 1 || adjustments[0].TokensAfter != 60 {
+		t.Fatalf("dry run = %+v, %v", adjustments, err)
+	}
+	if store.balance.Tokens != 80 {
+		t.Fatal("dry run changed the balance")
+	}
+
+	if _, err := Reconcile(ctx, store, usage, false); err != nil {
+		t.Fatal(err)
+	}
+	if store.balance.Credits != (accounts.Credits{Tokens: 60, ChargedTokens: 40}) {
+		t.Errorf("credits after reconcile = %+v", store.balance.Credits)
+	}
+	if adjustments, _ := Reconcile(ctx, store, usage, false); len(adjustments) != 0 {
+		t.Errorf("second reconcile made adjustments: %+v", adjustments)
+	}
+}
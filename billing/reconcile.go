@@ -0,0 +1,73 @@
+package billing
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/codevideo/codevideo-cli/accounts"
+	"github.com/codevideo/codevideo-cli/ledger"
+)
+
+// Adjustment is the correction reconciliation made (or would make) to one
+// account: the directory had charged DirectoryCharged tokens in total where
+// the ledger says LedgerCharged.
+type Adjustment struct {
+	ledger.Account
+	LedgerCharged    int
+	DirectoryCharged int
+	TokensBefore     int
+	TokensAfter      int
+	Err              error
+}
+
+// Reconcile brings the balances in the directory in line with the ledger:
+// charges the directory missed (e.g. lost to a concurrent metadata update) are
+// deducted, and charges it counted twice are refunded. Accounts that already
+// agree are left alone. With dryRun it only reports the adjustments.
+func Reconcile(ctx context.Context, directory accounts.UserDirectory, usage *ledger.Ledger, dryRun bool) ([]Adjustment, error) {
+	entries, err := usage.Entries()
+	if err != nil {
+		return nil, err
+	}
+	charged := ledger.Charged(entries)
+	keys := make([]ledger.Account, 0, len(charged))
+	for key := range charged {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Environment != keys[j].Environment {
+			return keys[i].Environment < keys[j].Environment
+		}
+		return keys[i].UserID < keys[j].UserID
+	})
+
+	var adjustments []Adjustment
+	for _, key := range keys {
+		account, err := directory.Account(ctx, key.Environment, key.UserID)
+		if err != nil {
+			adjustments = append(adjustments, Adjustment{Account: key, LedgerCharged: charged[key], Err: err})
+			continue
+		}
+		missing := charged[key] - account.ChargedTokens
+		if missing == 0 {
+			continue
+		}
+		adjustment := Adjustment{
+			Account:          key,
+			LedgerCharged:    charged[key],
+			DirectoryCharged: account.ChargedTokens,
+			TokensBefore:     account.Tokens,
+			TokensAfter:      account.Tokens - missing,
+		}
+		if !dryRun {
+			credits := account.Credits
+			credits.Tokens, credits.ChargedTokens = adjustment.TokensAfter, charged[key]
+			if err := directory.SetCredits(ctx, key.Environment, key.UserID, credits); err != nil {
+				adjustment.Err = fmt.Errorf("failed to update balance: %w", err)
+			}
+		}
+		adjustments = append(adjustments, adjustment)
+	}
+	return adjustments, nil
+}
@@ -11,16 +11,20 @@ import (
 	"github.com/codevideo/codevideo-cli/cli/config"
 	"github.com/codevideo/codevideo-cli/cli/detector"
 	"github.com/codevideo/codevideo-cli/cli/generator"
+	"github.com/codevideo/codevideo-cli/cli/renderer"
+	"github.com/codevideo/codevideo-cli/cli/staticserver"
 	"github.com/codevideo/codevideo-cli/server"
 	"github.com/codevideo/codevideo-cli/types"
 	"github.com/codevideo/codevideo-cli/utils"
 	"github.com/spf13/cobra"
 )
 
-// Execute runs the CLI workflow with the provided project data
-func Execute(cmd *cobra.Command) error {
+// Execute runs the CLI workflow with the provided project data, rendering
+// against the given static and manifest servers.
+func Execute(cmd *cobra.Command, endpoints staticserver.Endpoints) error {
 	// Load configuration from flags
-	if err := config.LoadFromFlags(cmd); err != nil {
+	cfg, err := config.LoadFromFlags(cmd)
+	if err != nil {
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
 
@@ -32,25 +36,25 @@ func Execute(cmd *cobra.Command) error {
 	}
 
 	// Store project JSON in config
-	config.GlobalConfig.ProjectJSON = projectJSON
+	cfg.ProjectJSON = projectJSON
 
 	// Load and validate config file if provided
 	var ideProps *types.CodeVideoIDEProps
-	if config.GlobalConfig.ConfigFilePath != "" {
-		var err error
-		ideProps, err = config.LoadConfigFile(config.GlobalConfig.ConfigFilePath)
+	if cfg.ConfigFilePath != "" {
+		ideProps, err = config.LoadConfigFile(cfg.ConfigFilePath)
 		if err != nil {
 			return fmt.Errorf("config validation failed: %w", err)
 		}
-		log.Printf("Loaded config from: %s", config.GlobalConfig.ConfigFilePath)
+		log.Printf("Loaded config from: %s", cfg.ConfigFilePath)
 	}
 
 	// Create a context with cancellation
 	ctx, cancel := context.WithCancel(context.Background())
 	setupCancellation(ctx, cancel)
 
-	log.Printf("Analyzing project JSON: %s", projectJSON)
-	course, lesson, actions, err := detector.DetectProjectType(projectJSON)
+	log.Printf("Analyzing project: %s", projectJSON)
+	projectType, _ := cmd.Flags().GetString("project-type")
+	course, lesson, actions, err := detector.DetectProjectType(projectJSON, projectType)
 	if err != nil {
 		return fmt.Errorf("failed to detect project type: %w", err)
 	}
@@ -59,13 +63,16 @@ func Execute(cmd *cobra.Command) error {
 	if ideProps != nil {
 		generator.IDEProps = ideProps
 	}
+	// every manifest carries the render settings it was generated with
+	render := cfg.Render()
+	generator.Render = &render
 
 	outputPath, _ := cmd.Flags().GetString("output")
 
 	if course != nil {
 		log.Printf("Starting Course workflow processing")
-		fmt.Println("Detected project type: Course")
-		fmt.Println("/> CodeVideo generation in progress...")
+		renderer.Messagef("Detected project type: Course")
+		renderer.Messagef("/> CodeVideo generation in progress...")
 		manifests := generator.GenerateFromCourse(*course)
 		// for each manifest, get its absolute path and call server.ProcessJob
 		for _, manifest := range manifests {
@@ -73,32 +80,32 @@ func Execute(cmd *cobra.Command) error {
 			if err != nil {
 				return fmt.Errorf("failed to save manifest: %w", err)
 			}
-			server.ProcessJob(manifestPath, "cli", outputPath)
+			server.ProcessJob(ctx, manifestPath, "cli", outputPath, render, endpoints)
 		}
 	}
 
 	if lesson != nil {
 		log.Printf("Starting Lesson workflow processing")
-		fmt.Println("Detected project type: Lesson")
-		fmt.Println("/> CodeVideo generation in progress...")
+		renderer.Messagef("Detected project type: Lesson")
+		renderer.Messagef("/> CodeVideo generation in progress...")
 		manifest := generator.GenerateFromLesson(*lesson)
 		manifestPath, err := generator.SaveManifest(manifest)
 		if err != nil {
 			return fmt.Errorf("failed to save manifest: %w", err)
 		}
-		server.ProcessJob(manifestPath, "cli", outputPath)
+		server.ProcessJob(ctx, manifestPath, "cli", outputPath, render, endpoints)
 	}
 
 	if actions != nil {
 		log.Printf("Starting Actions workflow processing")
-		fmt.Println("Detected project type: Actions")
-		fmt.Println("/> CodeVideo generation in progress...")
+		renderer.Messagef("Detected project type: Actions")
+		renderer.Messagef("/> CodeVideo generation in progress...")
 		manifest := generator.GenerateFromActions(*actions)
 		manifestPath, err := generator.SaveManifest(manifest)
 		if err != nil {
 			return fmt.Errorf("failed to save manifest: %w", err)
 		}
-		server.ProcessJob(manifestPath, "cli", outputPath)
+		server.ProcessJob(ctx, manifestPath, "cli", outputPath, render, endpoints)
 	}
 
 	// if the openFile (--open flag) was passed, open it!
@@ -117,7 +124,7 @@ func setupCancellation(ctx context.Context, cancel context.CancelFunc) {
 	c := make(chan os.Signal, 1)
 	go func() {
 		<-c
-		fmt.Println("\nCancelling operations...")
+		renderer.Messagef("\nCancelling operations...")
 		cancel()
 		// Give a little time for cleanup
 		time.Sleep(1 * time.Second)
@@ -0,0 +1,84 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/codevideo/codevideo-cli/settings"
+	"github.com/spf13/cobra"
+)
+
+// NewConfigCmd returns the "config" command group for inspecting settings.
+func NewConfigCmd() *cobra.Command {
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect the layered CodeVideo configuration",
+		Long: `Settings are resolved from, highest precedence first: command line flags,
+environment variables (including the .env file), the project file
+(CODEVIDEO_CONFIG or codevideo.yaml/.yml/.toml in the working directory), the
+user file (codevideo.yaml/.yml/.toml in the user config directory, e.g.
+~/.config/codevideo) and the built-in defaults.
+
+Config files have render, tts, storage, notify and server sections.`,
+	}
+	configCmd.AddCommand(newConfigShowCmd())
+	return configCmd
+}
+
+func newConfigShowCmd() *cobra.Command {
+	showCmd := &cobra.Command{
+		Use:   "show",
+		Short: "Show the config files in use and the values they set",
+		Long: `Show the config files in use and the values they set. With --resolved, show
+every setting's effective value and where it came from. Secrets are masked.
+
+The flags --resolution, --orientation, --frame-rate, --encoding-profile,
+--slack-progress and --metrics-addr can be passed to preview how they override
+the other layers.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			resolved, _ := cmd.Flags().GetBool("resolved")
+			config, err := settings.Load(cmd.Flags())
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			for _, file := range []struct{ label, path string }{{"Project file", config.ProjectFile}, {"User file", config.UserFile}} {
+				if file.path == "" {
+					file.path = "(none)"
+				}
+				fmt.Fprintf(out, "%s: %s\n", file.label, file.path)
+			}
+
+			w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+			for _, section := range settings.Sections {
+				printed := false
+				for _, value := range config.Values {
+					name, ok := strings.CutPrefix(value.Name, section+".")
+					if !ok {
+						continue
+					}
+					if !resolved && value.Source != settings.SourceProject && value.Source != settings.SourceUser {
+						continue
+					}
+					if !printed {
+						fmt.Fprintf(w, "\n[%s]\t\t\n", section)
+						printed = true
+					}
+					fmt.Fprintf(w, "  %s\t= %s\t(%s)\n", name, value.Display(), value.Describe())
+				}
+			}
+			return w.Flush()
+		},
+	}
+	showCmd.Flags().Bool("resolved", false, "Show every setting's effective value and its source")
+	showCmd.Flags().String("resolution", "", "Preview a --resolution flag")
+	showCmd.Flags().String("orientation", "", "Preview an --orientation flag")
+	showCmd.Flags().Int("frame-rate", 0, "Preview a --frame-rate flag")
+	showCmd.Flags().String("encoding-profile", "", "Preview an --encoding-profile flag")
+	showCmd.Flags().Bool("slack-progress", false, "Preview a --slack-progress flag")
+	showCmd.Flags().String("metrics-addr", "", "Preview a --metrics-addr flag")
+	return showCmd
+}
@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/codevideo/codevideo-cli/projectformat"
+	"github.com/spf13/cobra"
+)
+
+// NewConvertCmd returns the "convert" command, which converts projects
+// between JSON, YAML and Markdown.
+func NewConvertCmd() *cobra.Command {
+	convertCmd := &cobra.Command{
+		Use:   "convert <input>",
+		Short: "Convert a project between JSON, YAML and Markdown",
+		Long: `Convert actions, a lesson or a course between JSON, YAML and Markdown. Use -
+to read from stdin.
+
+The input format is taken from --from, the input's extension (.json, .yaml,
+.yml, .md) or its content; the output format from --to or the extension of
+--output. Without --output the result is printed. Courses can't be written as
+Markdown, which holds a single lesson.
+
+  codevideo convert data/lesson.json -o lesson.md
+  codevideo convert lesson.md --to json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fromName, _ := cmd.Flags().GetString("from")
+			toName, _ := cmd.Flags().GetString("to")
+			outputPath, _ := cmd.Flags().GetString("output")
+
+			input := args[0]
+			var data []byte
+			var err error
+			if input == "-" {
+				data, err = io.ReadAll(cmd.InOrStdin())
+			} else {
+				data, err = os.ReadFile(input)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", input, err)
+			}
+
+			from, ok := projectformat.ForPath(input)
+			if fromName != "" {
+				if from, err = projectformat.Parse(fromName); err != nil {
+					return err
+				}
+			} else if !ok {
+				if from, err = projectformat.Sniff(data); err != nil {
+					return fmt.Errorf("%s: %w (use --from to name its format)", input, err)
+				}
+			}
+
+			to, ok := projectformat.ForPath(outputPath)
+			if toName != "" {
+				if to, err = projectformat.Parse(toName); err != nil {
+					return err
+				}
+			} else if !ok {
+				return fmt.Errorf("--to is required unless --output has a .json, .yaml, .yml or .md extension")
+			}
+
+			converted, err := projectformat.Convert(data, from, to)
+			if err != nil {
+				return err
+			}
+			if outputPath == "" {
+				_, err = cmd.OutOrStdout().Write(converted)
+				return err
+			}
+			if err := os.WriteFile(outputPath, converted, 0644); err != nil {
+				return fmt.Errorf("failed to write %s: %w", outputPath, err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Converted %s (%s) to %s (%s)\n", input, from, outputPath, to)
+			return nil
+		},
+	}
+	convertCmd.Flags().String("from", "", "Input format: json, yaml or markdown (default: from the extension or content)")
+	convertCmd.Flags().String("to", "", "Output format: json, yaml or markdown (default: from the --output extension)")
+	convertCmd.Flags().StringP("output", "o", "", "Write the converted project to this file instead of printing it")
+	return convertCmd
+}
@@ -0,0 +1,278 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/codevideo/codevideo-cli/accounts"
+	"github.com/codevideo/codevideo-cli/constants"
+	"github.com/codevideo/codevideo-cli/health"
+	"github.com/codevideo/codevideo-cli/utils"
+	"github.com/spf13/cobra"
+)
+
+// doctor finding levels
+const (
+	doctorOK   = "ok"
+	doctorWarn = "warn"
+	doctorFail = "fail"
+)
+
+// doctorFinding is one line of the doctor report, with a hint on how to fix it.
+type doctorFinding struct {
+	level  string
+	label  string
+	detail string
+	hint   string
+}
+
+// NewDoctorCmd returns the "doctor" command, which diagnoses the local setup.
+func NewDoctorCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "doctor",
+		Short: "Check the environment for everything CodeVideo needs to render",
+		Long: `Report the resolved folders, ffmpeg, node and Chrome, the ports of the
+static and manifest servers, the loaded .env file and which credentials are set
+(values are never printed), with a hint for every problem found.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := context.WithTimeout(cmd.Context(), 30*time.Second)
+			defer cancel()
+
+			sections := []struct {
+				title    string
+				findings []doctorFinding
+			}{
+				{"Folders", doctorFolders(ctx)},
+				{"Tools", doctorTools(ctx)},
+				{"Ports", doctorPorts()},
+				{"Configuration", doctorEnv()},
+				{"Credentials", doctorCredentials()},
+			}
+
+			out := cmd.OutOrStdout()
+			failures, warnings := 0, 0
+			for _, section := range sections {
+				fmt.Fprintf(out, "\n%s\n", section.title)
+				for _, finding := range section.findings {
+					printFinding(out, finding)
+					switch finding.level {
+					case doctorFail:
+						failures++
+					case doctorWarn:
+						warnings++
+					}
+				}
+			}
+			fmt.Fprintln(out)
+
+			if failures > 0 {
+				return fmt.Errorf("%d problem(s) found, %d warning(s)", failures, warnings)
+			}
+			fmt.Fprintf(out, "✅ Ready to render (%d warning(s))\n", warnings)
+			return nil
+		},
+	}
+}
+
+func printFinding(w io.Writer, finding doctorFinding) {
+	icon := "✅"
+	switch finding.level {
+	case doctorWarn:
+		icon = "⚠️ "
+	case doctorFail:
+		icon = "❌"
+	}
+	fmt.Fprintf(w, "  %s %-22s %s\n", icon, finding.label, finding.detail)
+	if finding.hint != "" && finding.level != doctorOK {
+		fmt.Fprintf(w, "     ↳ %s\n", finding.hint)
+	}
+}
+
+func doctorFolders(ctx context.Context) []doctorFinding {
+	findings := []doctorFinding{{level: doctorOK, label: "Work folder", detail: constants.WorkFolder()}}
+	if err := health.WorkFoldersWritable(ctx); err != nil {
+		findings[0] = doctorFinding{doctorFail, "Work folder", err.Error(), "Set CODEVIDEO_WORK_DIR to a writable directory"}
+	}
+
+	findings = append(findings, writableFolder("Log folder", constants.LogFolder(), "Set CODEVIDEO_LOG_DIR to a writable directory"))
+	findings = append(findings, writableFolder("Output folder", constants.OutputFolder(), "Run codevideo from a writable directory or pass -o with a writable path"))
+
+	runnerPath := constants.PuppeteerRunnerPath()
+	if _, err := os.Stat(runnerPath); err != nil {
+		findings = append(findings, doctorFinding{doctorFail, "Puppeteer runner", runnerPath + " is missing",
+			"Run `npm install` in puppeteer-runner next to the binary, or set CODEVIDEO_PUPPETEER_RUNNER_PATH"})
+	} else {
+		findings = append(findings, doctorFinding{level: doctorOK, label: "Puppeteer runner", detail: runnerPath})
+	}
+	return findings
+}
+
+func writableFolder(label string, dir string, hint string) doctorFinding {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return doctorFinding{doctorFail, label, fmt.Sprintf("%s cannot be created: %v", dir, err), hint}
+	}
+	probe, err := os.CreateTemp(dir, ".doctor-*")
+	if err != nil {
+		return doctorFinding{doctorFail, label, fmt.Sprintf("%s is not writable", dir), hint}
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+	return doctorFinding{level: doctorOK, label: label, detail: dir}
+}
+
+func doctorTools(ctx context.Context) []doctorFinding {
+	var findings []doctorFinding
+
+	if ffmpegPath, err := utils.ResolveFFmpegPath(); err != nil {
+		findings = append(findings, doctorFinding{doctorFail, "ffmpeg", err.Error(),
+			"Install ffmpeg (e.g. `brew install ffmpeg` or `apt install ffmpeg`) or set CODEVIDEO_FFMPEG_PATH to its absolute path"})
+	} else {
+		findings = append(findings, doctorFinding{level: doctorOK, label: "ffmpeg", detail: fmt.Sprintf("%s (%s)", ffmpegPath, commandVersion(ctx, ffmpegPath, "-version"))})
+	}
+
+	if nodePath, err := utils.ResolveNodePath(); err != nil {
+		findings = append(findings, doctorFinding{doctorFail, "node", err.Error(), "Install Node.js 18 or newer from https://nodejs.org"})
+	} else {
+		findings = append(findings, doctorFinding{level: doctorOK, label: "node", detail: fmt.Sprintf("%s (%s)", nodePath, commandVersion(ctx, nodePath, "--version"))})
+	}
+
+	if chromePath, err := utils.ResolveChromePath(ctx); err != nil {
+		findings = append(findings, doctorFinding{doctorFail, "Chrome", err.Error(),
+			"Run `npx @fullstackcraftllc/codevideo-cli install-browser` or set CODEVIDEO_CHROME_PATH"})
+	} else {
+		findings = append(findings, doctorFinding{level: doctorOK, label: "Chrome", detail: chromePath})
+	}
+	return findings
+}
+
+// commandVersion returns the first line of a tool's version output.
+func commandVersion(ctx context.Context, path string, flag string) string {
+	output, err := exec.CommandContext(ctx, path, flag).Output()
+	if err != nil {
+		return "version unknown"
+	}
+	return strings.TrimSpace(strings.SplitN(string(output), "\n", 2)[0])
+}
+
+func doctorPorts() []doctorFinding {
+	var findings []doctorFinding
+	for _, port := range []struct {
+		number int
+		label  string
+		env    string
+	}{
+		{constants.ManifestServerPort(), "Manifest server", "CODEVIDEO_MANIFEST_PORT"},
+		{constants.StaticServerPort(), "Static server", "CODEVIDEO_STATIC_PORT"},
+	} {
+		if port.number == 0 {
+			findings = append(findings, doctorFinding{level: doctorOK, label: port.label, detail: "a free port is picked per process"})
+			continue
+		}
+		label := fmt.Sprintf("%s (%d)", port.label, port.number)
+		if utils.IsPortAvailable(port.number) {
+			findings = append(findings, doctorFinding{level: doctorOK, label: label, detail: "available"})
+		} else {
+			findings = append(findings, doctorFinding{doctorFail, label, "in use",
+				fmt.Sprintf("Stop the process using port %d (find it with `lsof -i :%d`) or unset %s to pick a free port", port.number, port.number, port.env)})
+		}
+	}
+	return findings
+}
+
+func doctorEnv() []doctorFinding {
+	envFile := constants.EnvFile()
+	source := "next to the executable"
+	if os.Getenv("CODEVIDEO_ENV_FILE") != "" {
+		source = "from CODEVIDEO_ENV_FILE"
+	}
+	switch {
+	case envFile == "":
+		return []doctorFinding{{doctorWarn, ".env file", "none loaded (using the process environment only)",
+			"Copy .env.example to .env next to the binary, or point CODEVIDEO_ENV_FILE at your env file"}}
+	case !fileExists(envFile):
+		return []doctorFinding{{doctorFail, ".env file", envFile + " does not exist", "Fix the path in CODEVIDEO_ENV_FILE"}}
+	default:
+		return []doctorFinding{{level: doctorOK, label: ".env file", detail: fmt.Sprintf("%s (%s)", envFile, source)}}
+	}
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+func doctorCredentials() []doctorFinding {
+	provider := constants.TTSProvider()
+	findings := []doctorFinding{{level: doctorOK, label: "TTS provider", detail: provider}}
+
+	// required wherever videos are rendered
+	switch provider {
+	case "elevenlabs":
+		findings = append(findings,
+			credential(doctorFail, "ElevenLabs", []string{"ELEVEN_LABS_API_KEY"}, "Create an API key at elevenlabs.io, or use CODEVIDEO_TTS_PROVIDER=kokoro with a local codevideo-tts"),
+			// ElevenLabs audio is uploaded to S3 before recording
+			credential(doctorFail, "S3 storage", []string{"CODEVIDEO_S3_KEY_ID", "CODEVIDEO_S3_SECRET"}, "Set the S3 access key pair used to store audio and videos"),
+		)
+	case "kokoro":
+		findings = append(findings, doctorFinding{level: doctorOK, label: "TTS service", detail: constants.TTSServiceURL()})
+		findings = append(findings, credential(doctorWarn, "S3 storage", []string{"CODEVIDEO_S3_KEY_ID", "CODEVIDEO_S3_SECRET"}, "Only needed in serve mode, where videos are uploaded to S3"))
+	default:
+		findings[0] = doctorFinding{doctorFail, "TTS provider", provider + " is not supported", "Set CODEVIDEO_TTS_PROVIDER to elevenlabs or kokoro"}
+	}
+
+	// only needed in serve mode
+	return append(findings,
+		credential(doctorWarn, "Mailjet", []string{"MJ_APIKEY_PUBLIC", "MJ_APIKEY_PRIVATE"}, "Only needed in serve mode, to email users"),
+		userDirectory(),
+		credential(doctorWarn, "Slack", []string{"SLACK_WEBHOOK_URL|CODEVIDEO_SLACK_WEBHOOK_URL"}, "Optional: set a webhook URL for job notifications"),
+	)
+}
+
+// userDirectory reports the serve-mode user directory and what it needs.
+func userDirectory() doctorFinding {
+	switch kind := constants.UserDirectory(); kind {
+	case accounts.KindClerk:
+		return credential(doctorWarn, "Clerk", []string{"CLERK_SECRET_KEY", "CLERK_SECRET_KEY_STAGING"}, "Only needed in serve mode, to look up users and their tokens")
+	case accounts.KindFile:
+		path := constants.UserDirectoryFile()
+		if !fileExists(path) {
+			return doctorFinding{doctorWarn, "User directory", path + " does not exist", "Create the users file or point CODEVIDEO_USERS_FILE at it"}
+		}
+		return doctorFinding{level: doctorOK, label: "User directory", detail: path}
+	case accounts.KindNone:
+		return doctorFinding{level: doctorOK, label: "User directory", detail: "none (users are unlimited and not emailed)"}
+	default:
+		return doctorFinding{doctorFail, "User directory", kind + " is not supported", "Set CODEVIDEO_USER_DIRECTORY to clerk, file or none"}
+	}
+}
+
+// credential reports which of the variables are set, without their values.
+// "A|B" means either variable will do.
+func credential(missingLevel string, label string, names []string, hint string) doctorFinding {
+	var set, missing []string
+	for _, name := range names {
+		found := ""
+		for _, alternative := range strings.Split(name, "|") {
+			if os.Getenv(alternative) != "" {
+				found = alternative
+				break
+			}
+		}
+		if found != "" {
+			set = append(set, found)
+		} else {
+			missing = append(missing, strings.ReplaceAll(name, "|", " or "))
+		}
+	}
+	if len(missing) == 0 {
+		return doctorFinding{level: doctorOK, label: label, detail: strings.Join(set, ", ") + " set"}
+	}
+	return doctorFinding{missingLevel, label, strings.Join(missing, ", ") + " not set", hint}
+}
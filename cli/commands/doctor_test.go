@@ -0,0 +1,28 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCredentialNeverPrintsValues(t *testing.T) {
+	t.Setenv("MJ_APIKEY_PUBLIC", "public-secret")
+	t.Setenv("MJ_APIKEY_PRIVATE", "")
+	t.Setenv("SLACK_WEBHOOK_URL", "")
+	t.Setenv("CODEVIDEO_SLACK_WEBHOOK_URL", "https://hooks.example.com/secret")
+
+	mailjet := credential(doctorWarn, "Mailjet", []string{"MJ_APIKEY_PUBLIC", "MJ_APIKEY_PRIVATE"}, "hint")
+	if mailjet.level != doctorWarn || mailjet.detail != "MJ_APIKEY_PRIVATE not set" {
+		t.Errorf("mailjet = %+v", mailjet)
+	}
+
+	slack := credential(doctorWarn, "Slack", []string{"SLACK_WEBHOOK_URL|CODEVIDEO_SLACK_WEBHOOK_URL"}, "hint")
+	if slack.level != doctorOK || slack.detail != "CODEVIDEO_SLACK_WEBHOOK_URL set" {
+		t.Errorf("slack = %+v", slack)
+	}
+	for _, finding := range []doctorFinding{mailjet, slack} {
+		if strings.Contains(finding.detail, "secret") {
+			t.Errorf("finding leaks a value: %q", finding.detail)
+		}
+	}
+}
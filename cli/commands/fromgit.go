@@ -0,0 +1,82 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/codevideo/codevideo-cli/gitlesson"
+	"github.com/codevideo/codevideo-cli/projectformat"
+	"github.com/spf13/cobra"
+)
+
+// NewFromGitCmd returns the "from-git" command, which generates a lesson or
+// course from a range of commits.
+func NewFromGitCmd() *cobra.Command {
+	fromGitCmd := &cobra.Command{
+		Use:   "from-git <repo> <range>",
+		Short: "Generate a lesson or course from git history",
+		Long: `Generate a lesson from the commits of a git revision range, e.g. v1.0..v2.0
+or main~10..main, for tutorials that build a project step by step. Merges are
+followed through their first parent.
+
+Each commit message is narrated with author-speak-before. New files are
+created, opened and typed out; modified files are edited line by line, with
+the replaced code highlighted first; deleted and renamed files are deleted and
+renamed in the file explorer. Files the range changes that existed before it
+are set up at the start of the lesson. Binary files are skipped.
+
+With --per-tag or --commits-per-lesson, the commits are split into the lessons
+of a course: a lesson ends at each tagged commit (and is named after the tag),
+or every N commits.
+
+The project is printed as JSON, or written to --output in the format of its
+extension (.json, .yaml, .yml or .md); render it with codevideo -p.
+
+  codevideo from-git ~/src/todo-app v0.1.0..v0.3.0 --per-tag -o todo-course.json`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name, _ := cmd.Flags().GetString("name")
+			commitsPerLesson, _ := cmd.Flags().GetInt("commits-per-lesson")
+			perTag, _ := cmd.Flags().GetBool("per-tag")
+			outputPath, _ := cmd.Flags().GetString("output")
+			if commitsPerLesson < 0 {
+				return fmt.Errorf("--commits-per-lesson must be positive")
+			}
+
+			project, err := gitlesson.Generate(cmd.Context(), args[0], args[1], gitlesson.Options{
+				Name:             name,
+				CommitsPerLesson: commitsPerLesson,
+				PerTag:           perTag,
+			})
+			if err != nil {
+				return err
+			}
+			data, err := json.MarshalIndent(project, "", "  ")
+			if err != nil {
+				return err
+			}
+			data = append(data, '\n')
+
+			if outputPath == "" {
+				_, err = cmd.OutOrStdout().Write(data)
+				return err
+			}
+			if format, ok := projectformat.ForPath(outputPath); ok {
+				if data, err = projectformat.FromJSON(data, format); err != nil {
+					return err
+				}
+			}
+			if err := os.WriteFile(outputPath, data, 0644); err != nil {
+				return fmt.Errorf("failed to write %s: %w", outputPath, err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Wrote %s of %s to %s\n", project.GetType(), args[1], outputPath)
+			return nil
+		},
+	}
+	fromGitCmd.Flags().String("name", "", "Name of the lesson or course (default: the repository's directory name)")
+	fromGitCmd.Flags().Int("commits-per-lesson", 0, "Start a new lesson every N commits, generating a course")
+	fromGitCmd.Flags().Bool("per-tag", false, "Start a new lesson after each tagged commit, generating a course")
+	fromGitCmd.Flags().StringP("output", "o", "", "Write the project to this file (.json, .yaml, .yml or .md) instead of printing it")
+	return fromGitCmd
+}
@@ -0,0 +1,113 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/codevideo/codevideo-cli/cli/importer"
+	"github.com/codevideo/codevideo-cli/projectformat"
+	"github.com/spf13/cobra"
+)
+
+// NewImportTerminalCmd returns the "import-terminal" command, which turns a
+// recorded shell session into terminal actions.
+func NewImportTerminalCmd() *cobra.Command {
+	importCmd := &cobra.Command{
+		Use:   "import-terminal <recording>",
+		Short: "Turn a recorded shell session into terminal actions",
+		Long: `Turn an asciinema .cast file (v2 or v3) or a script(1) typescript into
+terminal-open, terminal-type, terminal-enter and terminal-set-output actions.
+
+Commands are found by their prompt, which is removed; --prompt-pattern matches
+custom prompts and --prompt shows a fixed prompt in the video instead. Escape
+sequences are dropped and outputs longer than --max-output-lines keep their
+first and last lines. A final exit or logout isn't imported.
+
+Without --into the actions are printed as JSON. With --into, they are spliced
+into that lesson or actions project before action --at (appended by default),
+leaving its other properties as they are, and the project is printed in its
+own format. --output writes the result instead, converted to the format of its
+extension.
+
+  codevideo import-terminal setup.cast --into lesson.json --at 12 -o lesson.json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, _ := cmd.Flags().GetString("format")
+			maxOutputLines, _ := cmd.Flags().GetInt("max-output-lines")
+			promptPattern, _ := cmd.Flags().GetString("prompt-pattern")
+			prompt, _ := cmd.Flags().GetString("prompt")
+			intoPath, _ := cmd.Flags().GetString("into")
+			at, _ := cmd.Flags().GetInt("at")
+			outputPath, _ := cmd.Flags().GetString("output")
+
+			options := importer.Options{Format: format, MaxOutputLines: maxOutputLines, Prompt: prompt}
+			if maxOutputLines <= 0 {
+				options.MaxOutputLines = -1
+			}
+			if promptPattern != "" {
+				pattern, err := regexp.Compile(promptPattern)
+				if err != nil {
+					return fmt.Errorf("invalid --prompt-pattern: %w", err)
+				}
+				options.PromptPattern = pattern
+			}
+
+			recording, err := os.ReadFile(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", args[0], err)
+			}
+			actions, err := importer.Import(recording, options)
+			if err != nil {
+				return err
+			}
+
+			var data []byte
+			projectFormat := projectformat.JSON
+			if intoPath == "" {
+				if data, err = json.MarshalIndent(actions, "", "  "); err != nil {
+					return err
+				}
+				data = append(data, '\n')
+			} else {
+				project, err := os.ReadFile(intoPath)
+				if err != nil {
+					return fmt.Errorf("failed to read %s: %w", intoPath, err)
+				}
+				var ok bool
+				if projectFormat, ok = projectformat.ForPath(intoPath); !ok {
+					if projectFormat, err = projectformat.Sniff(project); err != nil {
+						return fmt.Errorf("%s: %w", intoPath, err)
+					}
+				}
+				if data, err = projectformat.InsertActions(project, projectFormat, at, actions); err != nil {
+					return fmt.Errorf("%s: %w", intoPath, err)
+				}
+			}
+
+			if outputPath == "" {
+				_, err = cmd.OutOrStdout().Write(data)
+				return err
+			}
+			if to, ok := projectformat.ForPath(outputPath); ok {
+				if data, err = projectformat.Convert(data, projectFormat, to); err != nil {
+					return err
+				}
+			}
+			if err := os.WriteFile(outputPath, data, 0644); err != nil {
+				return fmt.Errorf("failed to write %s: %w", outputPath, err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Wrote %d terminal actions from %s to %s\n", len(actions), args[0], outputPath)
+			return nil
+		},
+	}
+	importCmd.Flags().String("format", importer.FormatAuto, "Recording format: auto, asciinema or script")
+	importCmd.Flags().Int("max-output-lines", importer.DefaultMaxOutputLines, "Truncate outputs longer than this many lines (0 or less: no limit)")
+	importCmd.Flags().String("prompt-pattern", "", "Regular expression matching the recorded prompt at the start of a line (default: common shell prompts)")
+	importCmd.Flags().String("prompt", "", "Prompt to show in the video instead of the recorded one")
+	importCmd.Flags().String("into", "", "Lesson or actions project (JSON, YAML or Markdown) to splice the actions into")
+	importCmd.Flags().Int("at", -1, "Insert the actions before this action index of --into (default: append)")
+	importCmd.Flags().StringP("output", "o", "", "Write the result to this file (.json, .yaml, .yml or .md) instead of printing it")
+	return importCmd
+}
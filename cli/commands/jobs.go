@@ -0,0 +1,264 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/codevideo/codevideo-cli/constants"
+	"github.com/codevideo/codevideo-cli/jobs"
+	"github.com/spf13/cobra"
+)
+
+// NewJobsCmd returns the "jobs" command group for triaging jobs in the work folder.
+func NewJobsCmd() *cobra.Command {
+	jobsCmd := &cobra.Command{
+		Use:   "jobs",
+		Short: "List, inspect, requeue and purge render jobs",
+		Long: `Work with the render jobs in the work folder (CODEVIDEO_WORK_DIR).
+
+Jobs are manifests in the new, error and success folders watched by serve mode.
+Failed jobs stay in the error folder until they are requeued or purged.`,
+	}
+	jobsCmd.AddCommand(newJobsListCmd(), newJobsShowCmd(), newJobsRequeueCmd(), newJobsPurgeCmd())
+	return jobsCmd
+}
+
+func newJobsListCmd() *cobra.Command {
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List jobs, oldest first",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			filter, err := jobsFilterFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+			list, err := jobs.List(filter)
+			if err != nil {
+				return err
+			}
+
+			if len(list) == 0 {
+				fmt.Fprintf(cmd.OutOrStdout(), "No jobs found in %s\n", constants.WorkFolder())
+				return nil
+			}
+			now := time.Now()
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "UUID\tSTATE\tAGE\tERROR")
+			for _, job := range list {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", job.UUID, job.State, formatAge(job.Age(now)), truncate(job.Error, 80))
+			}
+			return w.Flush()
+		},
+	}
+	listCmd.Flags().StringSlice("state", nil, "Only list jobs in these states (new, error, success)")
+	listCmd.Flags().String("older-than", "", "Only list jobs last updated at least this long ago (e.g. 90m, 36h, 7d)")
+	listCmd.Flags().String("error", "", "Only list jobs whose error contains this text")
+	return listCmd
+}
+
+func newJobsShowCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "show <uuid>",
+		Short: "Show a job's state and manifest",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			job, err := jobs.Find(args[0])
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "UUID:     %s\n", job.UUID)
+			fmt.Fprintf(out, "State:    %s\n", job.State)
+			fmt.Fprintf(out, "Updated:  %s (%s ago)\n", job.Modified.Format(time.RFC3339), formatAge(job.Age(time.Now())))
+			fmt.Fprintf(out, "Manifest: %s\n", job.Path)
+			if job.Error != "" {
+				fmt.Fprintf(out, "Error:    %s\n", job.Error)
+			}
+			if job.Manifest == nil {
+				return nil
+			}
+			if job.Manifest.NotifyError != "" {
+				fmt.Fprintf(out, "Notify:   %s\n", job.Manifest.NotifyError)
+			}
+			for _, attempt := range job.Manifest.Attempts {
+				result := "ok"
+				if attempt.Error != "" {
+					result = attempt.Error
+				}
+				fmt.Fprintf(out, "Attempt:  %s #%d at %s (%dms): %s\n", attempt.Stage, attempt.Attempt, attempt.StartedAt.Format(time.RFC3339), attempt.DurationMs, result)
+			}
+			data, err := json.MarshalIndent(job.Manifest, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "\n%s\n", data)
+			return nil
+		},
+	}
+}
+
+func newJobsRequeueCmd() *cobra.Command {
+	requeueCmd := &cobra.Command{
+		Use:   "requeue [uuid]",
+		Short: "Clear a failed job's error and move it back to the new folder",
+		Long: `Clear a failed job's error and move its manifest back to the new folder,
+where a running server picks it up again. Use --all-failed to requeue every job
+in the error folder (optionally narrowed with --error).`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			allFailed, _ := cmd.Flags().GetBool("all-failed")
+			errorContains, _ := cmd.Flags().GetString("error")
+			if allFailed == (len(args) == 1) {
+				return fmt.Errorf("pass either a job uuid or --all-failed")
+			}
+
+			var toRequeue []jobs.Job
+			if allFailed {
+				list, err := jobs.List(jobs.Filter{States: []jobs.State{jobs.StateError}, ErrorContains: errorContains})
+				if err != nil {
+					return err
+				}
+				toRequeue = list
+			} else {
+				job, err := jobs.Find(args[0])
+				if err != nil {
+					return err
+				}
+				toRequeue = append(toRequeue, job)
+			}
+
+			failed := 0
+			for _, job := range toRequeue {
+				if err := jobs.Requeue(job); err != nil {
+					fmt.Fprintf(cmd.ErrOrStderr(), "❌ %v\n", err)
+					failed++
+					continue
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "Requeued job %s\n", job.UUID)
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d of %d job(s) could not be requeued", failed, len(toRequeue))
+			}
+			if len(toRequeue) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), "No failed jobs to requeue")
+			}
+			return nil
+		},
+	}
+	requeueCmd.Flags().Bool("all-failed", false, "Requeue every failed job")
+	requeueCmd.Flags().String("error", "", "With --all-failed, only requeue jobs whose error contains this text")
+	return requeueCmd
+}
+
+func newJobsPurgeCmd() *cobra.Command {
+	purgeCmd := &cobra.Command{
+		Use:   "purge",
+		Short: "Delete old finished jobs and their leftover video files",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !cmd.Flags().Changed("older-than") {
+				return fmt.Errorf("--older-than is required")
+			}
+			filter, err := jobsFilterFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+			if len(filter.States) == 0 {
+				// queued jobs are only purged when asked for explicitly
+				filter.States = []jobs.State{jobs.StateError, jobs.StateSuccess}
+			}
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+			list, err := jobs.List(filter)
+			if err != nil {
+				return err
+			}
+			for _, job := range list {
+				if dryRun {
+					fmt.Fprintf(cmd.OutOrStdout(), "Would purge %s job %s\n", job.State, job.UUID)
+					continue
+				}
+				if err := jobs.Purge(job); err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "Purged %s job %s\n", job.State, job.UUID)
+			}
+			if !dryRun {
+				fmt.Fprintf(cmd.OutOrStdout(), "Purged %d job(s)\n", len(list))
+			}
+			return nil
+		},
+	}
+	purgeCmd.Flags().String("older-than", "", "Purge jobs last updated at least this long ago (e.g. 36h, 30d)")
+	purgeCmd.Flags().StringSlice("state", nil, "States to purge (default error,success)")
+	purgeCmd.Flags().String("error", "", "Only purge jobs whose error contains this text")
+	purgeCmd.Flags().Bool("dry-run", false, "List what would be purged without deleting anything")
+	return purgeCmd
+}
+
+// jobsFilterFromFlags builds a filter from the --state, --older-than and --error flags.
+func jobsFilterFromFlags(cmd *cobra.Command) (jobs.Filter, error) {
+	var filter jobs.Filter
+	states, _ := cmd.Flags().GetStringSlice("state")
+	for _, name := range states {
+		state, err := jobs.ParseState(name)
+		if err != nil {
+			return filter, err
+		}
+		filter.States = append(filter.States, state)
+	}
+	if olderThan, _ := cmd.Flags().GetString("older-than"); olderThan != "" {
+		age, err := parseAge(olderThan)
+		if err != nil {
+			return filter, err
+		}
+		filter.OlderThan = age
+	}
+	filter.ErrorContains, _ = cmd.Flags().GetString("error")
+	return filter, nil
+}
+
+// parseAge parses a Go duration, additionally accepting whole days ("7d").
+func parseAge(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid age %q", value)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	age, err := time.ParseDuration(value)
+	if err != nil || age < 0 {
+		return 0, fmt.Errorf("invalid age %q (use e.g. 90m, 36h or 7d)", value)
+	}
+	return age, nil
+}
+
+func formatAge(age time.Duration) string {
+	switch {
+	case age >= 48*time.Hour:
+		return fmt.Sprintf("%dd", int(age.Hours()/24))
+	case age >= time.Hour:
+		return fmt.Sprintf("%dh", int(age.Hours()))
+	case age >= time.Minute:
+		return fmt.Sprintf("%dm", int(age.Minutes()))
+	default:
+		return fmt.Sprintf("%ds", int(age.Seconds()))
+	}
+}
+
+// truncate collapses whitespace and cuts s to at most limit runes.
+func truncate(s string, limit int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit-1]) + "…"
+}
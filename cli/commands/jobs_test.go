@@ -0,0 +1,28 @@
+package commands
+
+import (
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestParseAge(t *testing.T) {
+	for value, want := range map[string]time.Duration{"7d": 7 * 24 * time.Hour, "36h": 36 * time.Hour, "90m": 90 * time.Minute} {
+		if got, err := parseAge(value); err != nil || got != want {
+			t.Errorf("parseAge(%q) = %s, %v", value, got, err)
+		}
+	}
+	if _, err := parseAge("soon"); err == nil {
+		t.Error("parseAge should reject invalid ages")
+	}
+}
+
+func TestTruncateKeepsRunesWhole(t *testing.T) {
+	if got := truncate("short  error\n", 80); got != "short error" {
+		t.Errorf("truncate = %q", got)
+	}
+	got := truncate("ffmpeg failed: 文件不存在", 17)
+	if got != "ffmpeg failed: 文…" || !utf8.ValidString(got) {
+		t.Errorf("truncate = %q", got)
+	}
+}
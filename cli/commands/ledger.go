@@ -0,0 +1,135 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/codevideo/codevideo-cli/accounts"
+	"github.com/codevideo/codevideo-cli/billing"
+	"github.com/codevideo/codevideo-cli/constants"
+	"github.com/codevideo/codevideo-cli/ledger"
+	"github.com/spf13/cobra"
+)
+
+// NewLedgerCmd returns the "ledger" command group for token accounting.
+func NewLedgerCmd() *cobra.Command {
+	ledgerCmd := &cobra.Command{
+		Use:   "ledger",
+		Short: "Report token usage and reconcile balances with the user directory",
+		Long: `Work with the token usage ledger (CODEVIDEO_LEDGER_FILE, ledger.jsonl in the
+work folder by default).
+
+Serve mode appends one entry per delivered job with the tokens it was charged.
+The ledger is the source of truth for charges; balances in the user directory
+(CODEVIDEO_USER_DIRECTORY) are reconciled against it.`,
+	}
+	ledgerCmd.AddCommand(newLedgerReportCmd(), newLedgerReconcileCmd())
+	return ledgerCmd
+}
+
+func newLedgerReportCmd() *cobra.Command {
+	reportCmd := &cobra.Command{
+		Use:   "report",
+		Short: "Show jobs and tokens charged per user per month",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			month, _ := cmd.Flags().GetString("month")
+			if month != "" {
+				if _, err := time.Parse("2006-01", month); err != nil {
+					return fmt.Errorf("invalid month %q (use YYYY-MM)", month)
+				}
+			}
+			userID, _ := cmd.Flags().GetString("user")
+			asJSON, _ := cmd.Flags().GetBool("json")
+
+			entries, err := ledger.Open(constants.LedgerFile()).Entries()
+			if err != nil {
+				return err
+			}
+			var report []ledger.Usage
+			for _, usage := range ledger.MonthlyUsage(entries) {
+				if (month == "" || usage.Month == month) && (userID == "" || usage.UserID == userID) {
+					report = append(report, usage)
+				}
+			}
+
+			out := cmd.OutOrStdout()
+			if asJSON {
+				if report == nil {
+					report = []ledger.Usage{}
+				}
+				data, err := json.MarshalIndent(report, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(out, string(data))
+				return nil
+			}
+			if len(report) == 0 {
+				fmt.Fprintf(out, "No usage recorded in %s\n", constants.LedgerFile())
+				return nil
+			}
+			w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "MONTH\tENVIRONMENT\tUSER\tJOBS\tTOKENS")
+			for _, usage := range report {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\n", usage.Month, usage.Environment, usage.UserID, usage.Jobs, usage.Tokens)
+			}
+			return w.Flush()
+		},
+	}
+	reportCmd.Flags().String("month", "", "Only report this month (YYYY-MM, UTC)")
+	reportCmd.Flags().String("user", "", "Only report this user ID")
+	reportCmd.Flags().Bool("json", false, "Print the report as JSON")
+	return reportCmd
+}
+
+func newLedgerReconcileCmd() *cobra.Command {
+	reconcileCmd := &cobra.Command{
+		Use:   "reconcile",
+		Short: "Correct user directory balances that disagree with the ledger",
+		Long: `Compare the tokens each user was charged according to the ledger with the
+chargedTokens total in the user directory, and deduct (or refund) the
+difference from their tokens. Charges lost to concurrent updates are applied
+this way exactly once. Run it while no server is settling jobs for the same users.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			directory, err := accounts.New(constants.UserDirectory(), constants.UserDirectoryFile())
+			if err != nil {
+				return err
+			}
+
+			adjustments, err := billing.Reconcile(cmd.Context(), directory, ledger.Open(constants.LedgerFile()), dryRun)
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			failed := 0
+			for _, adjustment := range adjustments {
+				if adjustment.Err != nil {
+					fmt.Fprintf(cmd.ErrOrStderr(), "❌ %s/%s: %v\n", adjustment.Environment, adjustment.UserID, adjustment.Err)
+					failed++
+					continue
+				}
+				verb := "Adjusted"
+				if dryRun {
+					verb = "Would adjust"
+				}
+				fmt.Fprintf(out, "%s %s/%s: charged %d in the ledger, %d in the directory; tokens %d -> %d\n",
+					verb, adjustment.Environment, adjustment.UserID, adjustment.LedgerCharged, adjustment.DirectoryCharged, adjustment.TokensBefore, adjustment.TokensAfter)
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d account(s) could not be reconciled", failed)
+			}
+			if len(adjustments) == 0 {
+				fmt.Fprintln(out, "All balances agree with the ledger")
+			}
+			return nil
+		},
+	}
+	reconcileCmd.Flags().Bool("dry-run", false, "Show the adjustments without applying them")
+	return reconcileCmd
+}
@@ -0,0 +1,77 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/codevideo/codevideo-cli/mail"
+	"github.com/spf13/cobra"
+)
+
+// NewMailCmd returns the "mail" command group for working with notification emails.
+func NewMailCmd() *cobra.Command {
+	mailCmd := &cobra.Command{
+		Use:   "mail",
+		Short: "Work with notification email templates",
+	}
+	mailCmd.AddCommand(newMailPreviewCmd())
+	return mailCmd
+}
+
+func newMailPreviewCmd() *cobra.Command {
+	previewCmd := &cobra.Command{
+		Use:   "preview",
+		Short: "Render a notification email template to a file without sending it",
+		Long: `Render a notification email template to an HTML file for review.
+
+Templates are read from CODEVIDEO_MAIL_TEMPLATE_DIR when set (success.html,
+failure.html), falling back to the built-in templates.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			templateName, _ := cmd.Flags().GetString("template")
+			locale, _ := cmd.Flags().GetString("locale")
+			output, _ := cmd.Flags().GetString("output")
+			lesson, _ := cmd.Flags().GetString("lesson")
+			course, _ := cmd.Flags().GetString("course")
+			duration, _ := cmd.Flags().GetDuration("duration")
+			thumbnail, _ := cmd.Flags().GetString("thumbnail")
+			videoURL, _ := cmd.Flags().GetString("video-url")
+			errorSummary, _ := cmd.Flags().GetString("error")
+
+			subject, html, err := mail.Render(mail.Notification{
+				Template:     templateName,
+				Locale:       locale,
+				LessonName:   lesson,
+				CourseName:   course,
+				Duration:     duration,
+				ThumbnailURL: thumbnail,
+				VideoURL:     videoURL,
+				ErrorSummary: errorSummary,
+			})
+			if err != nil {
+				return err
+			}
+
+			if output == "" {
+				output = fmt.Sprintf("mail-preview-%s-%s.html", templateName, mail.NormalizeLocale(locale))
+			}
+			if err := os.WriteFile(output, []byte(html), 0644); err != nil {
+				return fmt.Errorf("failed to write preview: %w", err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Subject: %s\nPreview written to %s\n", subject, output)
+			return nil
+		},
+	}
+
+	previewCmd.Flags().StringP("template", "t", mail.TemplateSuccess, "Template to render (success or failure)")
+	previewCmd.Flags().StringP("locale", "l", mail.DefaultLocale, "Locale to render the template in (en, de, es, pt, zh)")
+	previewCmd.Flags().StringP("output", "o", "", "Output HTML file path (default mail-preview-<template>-<locale>.html)")
+	previewCmd.Flags().String("lesson", "Building an MCP Server with TypeScript", "Sample lesson name")
+	previewCmd.Flags().String("course", "TypeScript Track", "Sample course name")
+	previewCmd.Flags().Duration("duration", 4*time.Minute+32*time.Second, "Sample video duration")
+	previewCmd.Flags().String("thumbnail", "", "Sample thumbnail URL")
+	previewCmd.Flags().String("video-url", "https://example.com/codevideo.mp4", "Sample video URL")
+	previewCmd.Flags().String("error", "Puppeteer recording failed", "Sample error summary for the failure template")
+	return previewCmd
+}
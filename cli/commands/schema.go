@@ -0,0 +1,81 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/codevideo/codevideo-cli/types"
+	"github.com/spf13/cobra"
+)
+
+// NewSchemaCmd returns the "schema" command, which prints the JSON Schemas
+// generated from the Go types.
+func NewSchemaCmd() *cobra.Command {
+	schemaCmd := &cobra.Command{
+		Use:   "schema [type]",
+		Short: "Print the JSON Schema of a project, manifest or config type",
+		Long: `Print the JSON Schema of Action, Actions, Lesson, Course, CodeVideoManifest or
+CodeVideoIDEProps, generated from the Go types. Action names are checked
+against the actions the bundled player understands.
+
+Without a type, list the available schemas. With --out, write every schema
+(or just the given one) to a directory, e.g. to diff schema changes between
+releases in CI:
+
+  old/codevideo schema --out old && new/codevideo schema --out new && diff -r old new`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			outDir, _ := cmd.Flags().GetString("out")
+
+			schemaTypes := types.SchemaTypes
+			if len(args) == 1 {
+				schemaType, ok := types.LookupSchemaType(args[0])
+				if !ok {
+					names := make([]string, len(types.SchemaTypes))
+					for i, s := range types.SchemaTypes {
+						names[i] = s.Name
+					}
+					return fmt.Errorf("unknown type %q (use one of %s)", args[0], strings.Join(names, ", "))
+				}
+				schemaTypes = []types.SchemaType{schemaType}
+			}
+
+			out := cmd.OutOrStdout()
+			if outDir == "" && len(args) == 0 {
+				w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+				fmt.Fprintln(w, "TYPE\tFILE")
+				for _, s := range schemaTypes {
+					fmt.Fprintf(w, "%s\t%s\n", s.Name, s.File)
+				}
+				return w.Flush()
+			}
+
+			if outDir != "" {
+				if err := os.MkdirAll(outDir, 0755); err != nil {
+					return fmt.Errorf("failed to create %s: %w", outDir, err)
+				}
+			}
+			for _, s := range schemaTypes {
+				data, err := s.Schema().MarshalIndent()
+				if err != nil {
+					return err
+				}
+				if outDir == "" {
+					out.Write(data)
+					continue
+				}
+				path := filepath.Join(outDir, s.File)
+				if err := os.WriteFile(path, data, 0644); err != nil {
+					return fmt.Errorf("failed to write %s: %w", path, err)
+				}
+				fmt.Fprintf(out, "Wrote %s\n", path)
+			}
+			return nil
+		},
+	}
+	schemaCmd.Flags().String("out", "", "Write the schema files to this directory instead of printing them")
+	return schemaCmd
+}
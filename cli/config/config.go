@@ -1,14 +1,15 @@
 package config
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"time"
 
 	"github.com/codevideo/codevideo-cli/constants"
+	"github.com/codevideo/codevideo-cli/schema"
 	"github.com/codevideo/codevideo-cli/types"
 	"github.com/spf13/cobra"
 )
@@ -24,9 +25,11 @@ type Config struct {
 	OutputFormat   string
 
 	// Processing settings
-	Resolution  string
-	Orientation string
-	Debug       bool // Debug mode flag
+	Resolution      string
+	Orientation     string
+	FrameRate       int
+	Debug           bool // Debug mode flag
+	EncodingProfile string
 
 	// Environment
 	OperatingSystem string
@@ -36,63 +39,100 @@ type Config struct {
 	ConfigFilePath string
 }
 
-// Global configuration pointer
-var GlobalConfig *Config
-
-// Initialize with default configuration
-func init() {
-	GlobalConfig = DefaultConfig()
-}
-
 // DefaultConfig returns the default configuration
 func DefaultConfig() *Config {
 	return &Config{
 		OutputDir:       ".",
 		OutputFileName:  fmt.Sprintf("CodeVideo-%s", time.Now().Format("2006-01-02-15-04-05")),
 		OutputFormat:    "mp4",
-		Resolution:      "1080p",     // 1080p by default, could be 4K
-		Orientation:     "landscape", // Default to landscape
-		Debug:           false,       // Debug mode disabled by default
+		Resolution:      "1080p",                // 1080p by default; see types.ResolutionHelp for the others
+		Orientation:     "landscape",            // Default to landscape
+		FrameRate:       types.DefaultFrameRate, // 60 fps by default
+		Debug:           false,                  // Debug mode disabled by default
+		EncodingProfile: types.EncodingStandard,
 		OperatingSystem: runtime.GOOS,
 		Environment:     "local",
 	}
 }
 
-// LoadFromFlags updates the configuration from command flags
-func LoadFromFlags(cmd *cobra.Command) error {
+// LoadFromFlags returns the default configuration updated from command flags
+func LoadFromFlags(cmd *cobra.Command) (*Config, error) {
+	config := DefaultConfig()
+
 	// Read output path if provided
 	output, _ := cmd.Flags().GetString("output")
 	if output != "" {
-		GlobalConfig.OutputDir = filepath.Dir(output)
-		GlobalConfig.OutputFileName = filepath.Base(output)
+		config.OutputDir = filepath.Dir(output)
+		config.OutputFileName = filepath.Base(output)
 		// Remove extension if present
-		GlobalConfig.OutputFileName = GlobalConfig.OutputFileName[:len(GlobalConfig.OutputFileName)-len(filepath.Ext(GlobalConfig.OutputFileName))]
+		config.OutputFileName = config.OutputFileName[:len(config.OutputFileName)-len(filepath.Ext(config.OutputFileName))]
 	}
 
-	// Read resolution if provided
-	resolution, _ := cmd.Flags().GetString("resolution")
-	if resolution != "" {
-		GlobalConfig.Resolution = resolution
+	// Read resolution, orientation, frame rate and encoding profile: flags win
+	// over CODEVIDEO_RESOLUTION, CODEVIDEO_ORIENTATION, CODEVIDEO_FRAME_RATE and
+	// CODEVIDEO_ENCODING_PROFILE, which config files (render section) also set
+	if resolution := flagOrEnv(cmd, "resolution", "CODEVIDEO_RESOLUTION"); resolution != "" {
+		config.Resolution = resolution
 	}
-
-	// Read orientation if provided
-	orientation, _ := cmd.Flags().GetString("orientation")
-	if orientation != "" {
-		GlobalConfig.Orientation = orientation
+	if orientation := flagOrEnv(cmd, "orientation", "CODEVIDEO_ORIENTATION"); orientation != "" {
+		config.Orientation = orientation
+	}
+	if frameRate := flagOrEnv(cmd, "frame-rate", "CODEVIDEO_FRAME_RATE"); frameRate != "" {
+		n, err := strconv.Atoi(frameRate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid frame rate %q: use a whole number of frames per second, e.g. 30 or 60", frameRate)
+		}
+		config.FrameRate = n
+	}
+	if profile := flagOrEnv(cmd, "encoding-profile", "CODEVIDEO_ENCODING_PROFILE"); profile != "" {
+		config.EncodingProfile = profile
+	}
+	if err := config.Render().Validate(); err != nil {
+		return nil, fmt.Errorf("invalid render settings: %w", err)
 	}
 
 	// Read config file path if provided
 	configPath, _ := cmd.Flags().GetString("config")
 	if configPath != "" {
-		GlobalConfig.ConfigFilePath = configPath
+		config.ConfigFilePath = configPath
 	}
 
 	// Read debug flag if provided
 	debug, _ := cmd.Flags().GetBool("debug")
-	GlobalConfig.Debug = debug
+	config.Debug = debug
 
 	// Ensure output directories exist
-	return GlobalConfig.EnsureOutputDirs()
+	if err := config.EnsureOutputDirs(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// Render returns the render settings of the configuration, the defaults for
+// jobs that don't set their own.
+func (c *Config) Render() types.RenderSettings {
+	return types.RenderSettings{
+		Resolution:      c.Resolution,
+		Orientation:     c.Orientation,
+		FrameRate:       c.FrameRate,
+		Debug:           c.Debug,
+		EncodingProfile: c.EncodingProfile,
+	}
+}
+
+// flagOrEnv returns the flag's value if it was set, then the environment
+// variable, then the flag's default, as a string.
+func flagOrEnv(cmd *cobra.Command, flag string, env string) string {
+	var value string
+	if f := cmd.Flags().Lookup(flag); f != nil {
+		value = f.Value.String()
+	}
+	if !cmd.Flags().Changed(flag) {
+		if v := os.Getenv(env); v != "" {
+			return v
+		}
+	}
+	return value
 }
 
 // EnsureOutputDirs ensures that all required output directories exist
@@ -133,7 +173,9 @@ func (c *Config) GenerateOutputPath(suffix string) string {
 	return filepath.Join(c.OutputDir, fmt.Sprintf("%s.%s", filename, c.OutputFormat))
 }
 
-// LoadConfigFile loads and parses the configuration file
+// LoadConfigFile loads and strictly validates the configuration file against
+// types.CodeVideoIDEProps. Every problem (unknown fields, wrong types, values
+// out of range) is reported in one error.
 func LoadConfigFile(configPath string) (*types.CodeVideoIDEProps, error) {
 	if configPath == "" {
 		return nil, nil
@@ -150,19 +192,10 @@ func LoadConfigFile(configPath string) (*types.CodeVideoIDEProps, error) {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
-	// Parse JSON
+	// Parse and validate JSON
 	var config types.CodeVideoIDEProps
-	if err := json.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse config JSON: %w", err)
-	}
-
-	// Validate required fields
-	if config.Theme != "light" && config.Theme != "dark" {
-		return nil, fmt.Errorf("theme must be 'light' or 'dark', got: %s", config.Theme)
-	}
-
-	if config.DefaultLanguage == "" {
-		return nil, fmt.Errorf("defaultLanguage is required")
+	if err := schema.Decode(data, &config); err != nil {
+		return nil, fmt.Errorf("%s does not match schema/codevideo-ide-props.schema.json: %w", configPath, err)
 	}
 
 	return &config, nil
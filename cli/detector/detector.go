@@ -1,43 +1,215 @@
 package detector
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"path"
+	"strings"
 
+	"github.com/codevideo/codevideo-cli/projectformat"
+	"github.com/codevideo/codevideo-cli/schema"
 	"github.com/codevideo/codevideo-cli/types"
 )
 
-// DetectProjectType determines the type of project from a JSON string
-func DetectProjectType(jsonData string) (*types.Course, *types.Lesson, *[]types.Action, error) {
-	// First try to unmarshal as Course
-	var course types.Course
-	err := json.Unmarshal([]byte(jsonData), &course)
-	if err == nil && len(course.Lessons) > 0 {
-		return &course, nil, nil, nil
+// ProjectTypeAuto detects the project type from the JSON when the project
+// doesn't declare one.
+const ProjectTypeAuto = "auto"
+
+// maxFindings is the most schema problems reported per candidate type.
+const maxFindings = 5
+
+// candidates are the project types in the order they are tried.
+var candidates = []string{types.ProjectTypeCourse, types.ProjectTypeLesson, types.ProjectTypeActions}
+
+// DetectProjectType determines the type of project from a JSON, YAML or
+// Markdown string (see projectformat). The type is taken from projectType (the --project-type flag) unless it's empty
+// or "auto", then from the project's "type" property, then from its
+// "$schema" (e.g. lesson.schema.json). Undeclared projects are tried as a
+// Course, a Lesson and Actions; if none fits, the error explains what each
+// attempt found.
+func DetectProjectType(projectData string, projectType string) (*types.Course, *types.Lesson, *[]types.Action, error) {
+	data, err := projectformat.Normalize([]byte(projectData))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	var document interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid project JSON: %w", err)
+	}
+
+	declared, source, err := declaredType(document, projectType)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if declared != "" {
+		course, lesson, actions, reason := parse(declared, data, document)
+		if reason != "" {
+			return nil, nil, nil, fmt.Errorf("project declared as %s by %s is not a valid %s: %s", title(declared), source, title(declared), reason)
+		}
+		return course, lesson, actions, nil
+	}
+
+	var findings []string
+	for _, candidate := range candidates {
+		course, lesson, actions, reason := parse(candidate, data, document)
+		if reason == "" {
+			return course, lesson, actions, nil
+		}
+		article := "a "
+		if candidate == types.ProjectTypeActions {
+			article = ""
+		}
+		findings = append(findings, fmt.Sprintf("  as %s%s: %s", article, title(candidate), strings.ReplaceAll(reason, "\n", "\n    ")))
+	}
+	return nil, nil, nil, fmt.Errorf("unable to determine project type; declare it with \"type\" (course or lesson) or --project-type.\n%s", strings.Join(findings, "\n"))
+}
+
+// declaredType returns the project type set by the flag, "type" or "$schema",
+// and what set it.
+func declaredType(document interface{}, projectType string) (string, string, error) {
+	if projectType != "" && !strings.EqualFold(projectType, ProjectTypeAuto) {
+		normalized, ok := normalize(projectType)
+		if !ok {
+			return "", "", fmt.Errorf("unknown --project-type %q (use auto, course, lesson or actions)", projectType)
+		}
+		return normalized, "--project-type", nil
+	}
+
+	object, ok := document.(map[string]interface{})
+	if !ok {
+		return "", "", nil
+	}
+	if value, ok := object["type"]; ok {
+		s, _ := value.(string)
+		normalized, ok := normalize(s)
+		if !ok {
+			return "", "", fmt.Errorf("unknown project \"type\" %v (use course or lesson)", value)
+		}
+		return normalized, "\"type\"", nil
+	}
+	if value, ok := object["$schema"].(string); ok {
+		for _, schemaType := range types.SchemaTypes {
+			if path.Base(value) == schemaType.File && schemaType.Discriminator != "" {
+				return schemaType.Discriminator, "\"$schema\"", nil
+			}
+		}
+	}
+	return "", "", nil
+}
+
+func normalize(projectType string) (string, bool) {
+	for _, candidate := range candidates {
+		if strings.EqualFold(projectType, candidate) {
+			return candidate, true
+		}
 	}
+	return "", false
+}
 
-	// Next try to unmarshal as Lesson
-	var lesson types.Lesson
-	err = json.Unmarshal([]byte(jsonData), &lesson)
-	if err == nil && len(lesson.Actions) > 0 {
-		return nil, &lesson, nil, nil
+func title(projectType string) string {
+	switch projectType {
+	case types.ProjectTypeCourse:
+		return types.Course{}.GetType()
+	case types.ProjectTypeLesson:
+		return types.Lesson{}.GetType()
 	}
+	return types.ActionsProject{}.GetType()
+}
 
-	// Finally try to unmarshal as Actions
-	var actions []types.Action
-	err = json.Unmarshal([]byte(jsonData), &actions)
-	if err == nil && len(actions) > 0 {
-		// Validate each action
-		for _, action := range actions {
+// parse reads data as the given project type. When it doesn't hold a usable
+// project of that type, reason says why, followed by the problems a strict
+// check against the type's schema finds.
+func parse(projectType string, data []byte, document interface{}) (*types.Course, *types.Lesson, *[]types.Action, string) {
+	switch projectType {
+	case types.ProjectTypeCourse:
+		var course types.Course
+		if err := json.Unmarshal(data, &course); err != nil {
+			return nil, nil, nil, schemaMismatch(err, document, &types.Course{})
+		}
+		if len(course.Lessons) == 0 {
+			return nil, nil, nil, withFindings("it has no lessons", document, &types.Course{})
+		}
+		return &course, nil, nil, ""
+
+	case types.ProjectTypeLesson:
+		var lesson types.Lesson
+		if err := json.Unmarshal(data, &lesson); err != nil {
+			return nil, nil, nil, schemaMismatch(err, document, &types.Lesson{})
+		}
+		if len(lesson.Actions) == 0 {
+			return nil, nil, nil, withFindings("it has no actions", document, &types.Lesson{})
+		}
+		return nil, &lesson, nil, ""
+
+	default:
+		var actions []types.Action
+		if err := json.Unmarshal(data, &actions); err != nil {
+			return nil, nil, nil, schemaMismatch(err, document, &[]types.Action{})
+		}
+		if len(actions) == 0 {
+			return nil, nil, nil, withFindings("the list is empty", document, &[]types.Action{})
+		}
+		for i, action := range actions {
 			if !types.IsValidAction(action) {
-				return nil, nil, nil, fmt.Errorf("invalid action detected: %v", action)
+				return nil, nil, nil, fmt.Sprintf("invalid action detected at index %d: %v (actions need a name and a value)", i, action)
 			}
 		}
 		actions = types.ActionsProject(actions)
-		return nil, nil, &actions, nil
+		return nil, nil, &actions, ""
+	}
+}
+
+// withFindings appends the schema problems of the document, decoded into v,
+// to reason.
+func withFindings(reason string, document interface{}, v interface{}) string {
+	if findings := schemaFindings(document, v); len(findings) > 0 {
+		return reason + "\n" + strings.Join(findings, "\n")
 	}
+	return reason
+}
 
-	return nil, nil, nil, fmt.Errorf("unable to determine project type: %v", err)
+// schemaMismatch explains a document that doesn't unmarshal into v with its
+// schema problems, or with err when the schema finds none.
+func schemaMismatch(err error, document interface{}, v interface{}) string {
+	if findings := schemaFindings(document, v); len(findings) > 0 {
+		return "it doesn't match the schema\n" + strings.Join(findings, "\n")
+	}
+	return err.Error()
+}
+
+// schemaFindings lists the problems a strict check of the document against
+// the schema of v finds, at most maxFindings of them. The "type"
+// discriminator isn't a problem.
+func schemaFindings(document interface{}, v interface{}) []string {
+	if object, ok := document.(map[string]interface{}); ok {
+		stripped := make(map[string]interface{}, len(object))
+		for key, value := range object {
+			if key != "type" {
+				stripped[key] = value
+			}
+		}
+		document = stripped
+	}
+	var buffer bytes.Buffer
+	if err := json.NewEncoder(&buffer).Encode(document); err != nil {
+		return nil
+	}
+
+	var validation *schema.ValidationError
+	if err := schema.Decode(buffer.Bytes(), v); !errors.As(err, &validation) {
+		return nil
+	}
+	var findings []string
+	for i, problem := range validation.Problems {
+		if i == maxFindings {
+			findings = append(findings, fmt.Sprintf("- and %d more", len(validation.Problems)-maxFindings))
+			break
+		}
+		findings = append(findings, "- "+problem.String())
+	}
+	return findings
 }
 
 // IsCourse checks if a project is a Course
@@ -0,0 +1,66 @@
+package detector
+
+import (
+	"strings"
+	"testing"
+)
+
+const lessonJSON = `{"id": "intro", "name": "Intro", "actions": [{"name": "author-speak-before", "value": "Hello!"}]}`
+
+func TestDetectProjectTypeHonorsDeclarations(t *testing.T) {
+	tests := []struct {
+		name        string
+		json        string
+		projectType string
+		wantLesson  bool
+		wantErr     string
+	}{
+		{name: "detected", json: lessonJSON, wantLesson: true},
+		{name: "type", json: `{"type": "lesson", "id": "intro", "name": "Intro", "actions": [{"name": "author-speak-before", "value": "Hi"}]}`, wantLesson: true},
+		{name: "schema", json: `{"$schema": "./schema/lesson.schema.json", "name": "Intro", "actions": [{"name": "author-speak-before", "value": "Hi"}]}`, wantLesson: true},
+		{name: "yaml", json: "type: lesson\nname: Intro\nactions:\n  - name: author-speak-before\n    value: Hi\n", wantLesson: true},
+		{name: "markdown", json: "# Intro\n\nHi\n", wantLesson: true},
+		{name: "flag", json: lessonJSON, projectType: "Lesson", wantLesson: true},
+		{name: "bare filename", json: "lesson.json", wantErr: "not a JSON, YAML or Markdown project"},
+		{name: "random text", json: "oops not a project", wantErr: "not a JSON, YAML or Markdown project"},
+		{name: "flag overrides detection", json: lessonJSON, projectType: "course", wantErr: "project declared as Course by --project-type is not a valid Course: it has no lessons"},
+		{name: "declared but invalid", json: `{"type": "course", "name": "Course", "lessons": []}`, wantErr: "declared as Course by \"type\""},
+		{name: "unknown type", json: `{"type": "tutorial"}`, wantErr: `unknown project "type" tutorial`},
+		{name: "unknown flag", json: lessonJSON, projectType: "video", wantErr: `unknown --project-type "video"`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, lesson, _, err := DetectProjectType(test.json, test.projectType)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("error = %v, want it to contain %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (lesson != nil) != test.wantLesson {
+				t.Errorf("lesson = %v, want a lesson: %v", lesson, test.wantLesson)
+			}
+		})
+	}
+}
+
+func TestDetectProjectTypeExplainsEachCandidate(t *testing.T) {
+	_, _, _, err := DetectProjectType(`{"name": "Intro", "actoins": [{"name": "author-speak-before", "value": "Hi"}]}`, ProjectTypeAuto)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, want := range []string{
+		"as a Course: it has no lessons",
+		"as a Lesson: it has no actions",
+		"actoins: unknown field",
+		"actions: is required",
+		"as Actions:",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+}
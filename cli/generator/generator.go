@@ -14,15 +14,14 @@ import (
 
 	log "github.com/sirupsen/logrus"
 
-	"github.com/codevideo/codevideo-cli/cli/renderer"
 	"github.com/codevideo/codevideo-cli/cloud"
 	"github.com/codevideo/codevideo-cli/constants"
 	"github.com/codevideo/codevideo-cli/elevenlabs"
+	"github.com/codevideo/codevideo-cli/progress"
+	"github.com/codevideo/codevideo-cli/retry"
 	"github.com/codevideo/codevideo-cli/types"
 	"github.com/codevideo/codevideo-cli/utils"
 	"github.com/google/uuid"
-
-	slack "github.com/codevideo/go-utils/slack"
 )
 
 // Generator handles the creation of CodeVideo manifests
@@ -30,6 +29,7 @@ type Generator struct {
 	Environment string
 	UserID      string
 	IDEProps    *types.CodeVideoIDEProps
+	Render      *types.RenderSettings
 }
 
 // NewGenerator creates a new manifest generator
@@ -50,7 +50,7 @@ func (g *Generator) GenerateFromActions(actions []types.Action) *types.CodeVideo
 	// Generate a unique UUID for this manifest
 	uuid := uuid.New().String()
 
-	// Slack log a nice "/> CodeVideo" logo
+	// log a nice "/> CodeVideo" logo
 	logo := `
 
 
@@ -59,18 +59,16 @@ func (g *Generator) GenerateFromActions(actions []types.Action) *types.CodeVideo
 
 `
 	log.Print(logo)
-	slack.SendSlackMessage(logo)
 
 	ramUsage, err := utils.GetRAMUsage()
 	if err != nil {
 		log.Printf("Failed to get RAM usage: %v", err)
 	}
-	environmentEnv := strings.ToUpper(os.Getenv("ENVIRONMENT"))
-	message := fmt.Sprintf("%s: Processing video job: %s (Job has %d actions; RAM usage is at %s)", environmentEnv, uuid, len(actions), ramUsage)
-	log.Print(message)
-	slack.SendSlackMessage(message)
+	message := fmt.Sprintf("Processing video job (Job has %d actions; RAM usage is at %s)", len(actions), ramUsage)
+	log.Printf("Job %s: %s", uuid, message)
+	progress.Publish(progress.Event{JobUUID: uuid, Stage: progress.StageAudio, TotalActions: len(actions), Message: message})
 
-	audioItems, err := generateAudioItems(actions)
+	audioItems, attempts, err := generateAudioItems(uuid, actions)
 	if err != nil {
 		log.Fatalf("Error generating audio items: %v", err)
 	}
@@ -81,13 +79,16 @@ func (g *Generator) GenerateFromActions(actions []types.Action) *types.CodeVideo
 		UUID:              uuid,
 		Actions:           actions,
 		AudioItems:        audioItems,
+		Attempts:          attempts,
+		Render:            g.Render,
 		CodeVideoIDEProps: g.IDEProps,
 	}
 }
 
 // GenerateFromLesson creates a manifest from a lesson
 func (g *Generator) GenerateFromLesson(lesson types.Lesson) *types.CodeVideoManifest {
-	audioItems, err := generateAudioItems(lesson.Actions)
+	uuid := uuid.New().String()
+	audioItems, attempts, err := generateAudioItems(uuid, lesson.Actions)
 	if err != nil {
 		log.Fatalf("Error generating audio items: %v", err)
 	}
@@ -95,9 +96,11 @@ func (g *Generator) GenerateFromLesson(lesson types.Lesson) *types.CodeVideoMani
 	return &types.CodeVideoManifest{
 		Environment:       g.Environment,
 		UserID:            g.UserID,
-		UUID:              uuid.New().String(),
+		UUID:              uuid,
 		Lesson:            lesson,
 		AudioItems:        audioItems,
+		Attempts:          attempts,
+		Render:            g.Render,
 		CodeVideoIDEProps: g.IDEProps,
 	}
 }
@@ -107,7 +110,9 @@ func (g *Generator) GenerateFromCourse(course types.Course) []*types.CodeVideoMa
 	var manifests []*types.CodeVideoManifest
 
 	for _, lesson := range course.Lessons {
-		manifests = append(manifests, g.GenerateFromLesson(lesson))
+		manifest := g.GenerateFromLesson(lesson)
+		manifest.CourseName = course.Name
+		manifests = append(manifests, manifest)
 	}
 
 	return manifests
@@ -141,17 +146,24 @@ func (g *Generator) SaveManifest(manifest *types.CodeVideoManifest) (string, err
 
 // generateAudioItems processes the given actions. For each action whose name starts with
 // "author-speak", it converts the text to audio via ElevenLabs and uploads the audio file to S3.
-func generateAudioItems(actions []types.Action) ([]types.AudioItem, error) {
-	progress := 0.0
-	renderer.RenderProgressToConsole(progress, "Generating audio for speaking actions...")
+// Failed attempts of the audio stage are returned for the job's attempt history.
+func generateAudioItems(jobUUID string, actions []types.Action) ([]types.AudioItem, []types.JobAttempt, error) {
+	publishAudioProgress := func(percent float64, actionIndex int, message string) {
+		progress.Publish(progress.Event{
+			JobUUID:      jobUUID,
+			Stage:        progress.StageAudio,
+			Percent:      percent,
+			ActionIndex:  actionIndex,
+			TotalActions: len(actions),
+			Message:      message,
+		})
+	}
+	publishAudioProgress(0, 0, "Generating audio for speaking actions...")
 	var audioManifest []types.AudioItem
 
 	// Provider switch: "elevenlabs" (default, cloud + S3) or "kokoro" (self-hosted
 	// codevideo-tts service, embedded as a data URI — no S3/cloud account needed).
-	provider := strings.ToLower(strings.TrimSpace(os.Getenv("CODEVIDEO_TTS_PROVIDER")))
-	if provider == "" {
-		provider = "elevenlabs"
-	}
+	provider := constants.TTSProvider()
 	ttsApiKey := os.Getenv("ELEVEN_LABS_API_KEY")
 	ttsVoiceId := resolveTTSVoiceID()
 	if provider == "elevenlabs" {
@@ -164,44 +176,73 @@ func generateAudioItems(actions []types.Action) ([]types.AudioItem, error) {
 		log.Printf("Using self-hosted TTS provider %q (audio embedded as data URI, no S3)", provider)
 	}
 
+	audioPolicy := retry.Policies(nil)[retry.StageAudio]
+	// only failed attempts are kept in the job history, one per retried item
+	var failedAttempts []types.JobAttempt
+	recordFailedAttempt := func(attempt types.JobAttempt) {
+		if attempt.Error != "" {
+			failedAttempts = append(failedAttempts, attempt)
+		}
+	}
+
+	// speech already synthesized for this job, so repeated text is synthesized once
+	jobAudio := make(map[string]types.AudioItem)
+
 	for i, action := range actions {
 		textToSpeak := action.Value
 		// Include voice ID in the hash so changing voices always generates new audio objects.
 		textHash := utils.Sha256Hash(fmt.Sprintf("%s::%s", ttsVoiceId, textToSpeak))
-		if strings.HasPrefix(action.Name, "author-speak") {
+		cacheKey := provider + "::" + textHash
+		if item, ok := jobAudio[cacheKey]; ok && strings.HasPrefix(action.Name, "author-speak") {
+			audioManifest = append(audioManifest, item)
+		} else if strings.HasPrefix(action.Name, "author-speak") {
 			log.Printf("Converting text at step index %d to audio... (hash is %s)\n", i, textHash)
 			var mp3Url string
-			if provider == "kokoro" {
-				audioData, err := getAudioFromKokoro(textToSpeak)
-				if err != nil {
-					return nil, fmt.Errorf("error converting text to audio via kokoro: %w", err)
+			var audioData []byte
+			// each item is retried on its own so items that already succeeded are reused
+			err := retry.Do(context.Background(), retry.StageAudio, audioPolicy, func(attempt int) error {
+				var err error
+				if provider == "kokoro" {
+					audioData, err = getAudioFromKokoro(textToSpeak)
+					if err != nil {
+						return fmt.Errorf("error converting text to audio via kokoro: %w", err)
+					}
+					mp3Url = "data:audio/mpeg;base64," + base64.StdEncoding.EncodeToString(audioData)
+					return nil
 				}
-				mp3Url = "data:audio/mpeg;base64," + base64.StdEncoding.EncodeToString(audioData)
-			} else {
-				audioData, err := elevenlabs.GetAudioArrayBufferElevenLabs(textToSpeak, ttsApiKey, ttsVoiceId)
+				audioData, err = elevenlabs.GetAudioArrayBufferElevenLabs(textToSpeak, ttsApiKey, ttsVoiceId)
 				if err != nil {
-					return nil, fmt.Errorf("error converting text to audio: %w", err)
+					return fmt.Errorf("error converting text to audio: %w", err)
 				}
-				ctx := context.Background()
-				mp3Url, err = cloud.UploadFileToS3(ctx, audioData, "v3/audio", fmt.Sprintf("%s.mp3", textHash))
+				mp3Url, err = cloud.UploadFileToS3(context.Background(), audioData, "v3/audio", fmt.Sprintf("%s.mp3", textHash))
 				if err != nil {
-					return nil, fmt.Errorf("error uploading audio to S3: %w", err)
+					return fmt.Errorf("error uploading audio to S3: %w", err)
 				}
+				return nil
+			}, recordFailedAttempt)
+			if err != nil {
+				return nil, nil, err
 			}
-			audioManifest = append(audioManifest, types.AudioItem{
-				Text:   textToSpeak,
-				Mp3Url: mp3Url,
-			})
+			duration, err := utils.EstimateMp3Duration(audioData)
+			if err != nil {
+				log.Printf("Failed to estimate audio duration at step index %d: %v", i, err)
+			}
+			item := types.AudioItem{
+				Text:       textToSpeak,
+				Mp3Url:     mp3Url,
+				DurationMs: duration.Milliseconds(),
+			}
+			jobAudio[cacheKey] = item
+			audioManifest = append(audioManifest, item)
 		}
 		// since audio is only about 10% of the total time, we'll cap the max progress at 10%
-		progress = (float64(i) / float64(len(actions)) * 10)
-		renderer.RenderProgressToConsole(progress, "Generating audio for speaking actions...")
+		publishAudioProgress(float64(i)/float64(len(actions))*10, i, "Generating audio for speaking actions...")
 	}
 
 	log.Printf("Done with audio conversion\n")
-	renderer.RenderProgressToConsole(10, "Done with audio generation")
+	publishAudioProgress(10, len(actions), "Done with audio generation")
 
-	return audioManifest, nil
+	return audioManifest, failedAttempts, nil
 }
 
 // getAudioFromKokoro synthesizes speech via the self-hosted codevideo-tts
@@ -209,10 +250,7 @@ func generateAudioItems(actions []types.Action) ([]types.AudioItem, error) {
 // TTS_SERVICE_URL points at the service (default http://localhost:3000); an
 // optional TTS_API_KEY is sent as a Bearer token.
 func getAudioFromKokoro(text string) ([]byte, error) {
-	base := strings.TrimRight(os.Getenv("TTS_SERVICE_URL"), "/")
-	if base == "" {
-		base = "http://localhost:3000"
-	}
+	base := constants.TTSServiceURL()
 	payload, err := json.Marshal(map[string]interface{}{
 		"input":           text,
 		"response_format": "mp3",
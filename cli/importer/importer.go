@@ -0,0 +1,267 @@
+// Package importer turns recorded shell sessions, asciinema .cast files and
+// script(1) typescripts, into terminal actions that can be spliced into a
+// lesson.
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/codevideo/codevideo-cli/types"
+)
+
+// Session formats.
+const (
+	FormatAuto      = "auto"
+	FormatAsciinema = "asciinema"
+	FormatScript    = "script"
+)
+
+// DefaultMaxOutputLines is the default number of output lines kept per
+// command.
+const DefaultMaxOutputLines = 20
+
+// DefaultPromptPattern matches common shell prompts at the start of a line:
+// "$ ", "user@host:~/app$ ", "user@host ~ % ", "(venv) ~/app $ ", "❯ " and
+// oh-my-zsh's "➜  app git:(main) ✗ ".
+var DefaultPromptPattern = regexp.MustCompile(`^(?:\([^)]*\)\s*)?(?:[\w.-]+@[\w.-]+(?::?\s?[~/][^\s$#%]*)?\s?[$#%]|[~/][^\s$#%]*\s?[$#%]|[$❯]|➜\s+\S+(?:\s+git:\([^)]*\))?(?:\s+✗)?)\s+`)
+
+// exitCommands end a recording and aren't imported.
+var exitCommands = map[string]bool{"exit": true, "logout": true}
+
+// Options controls how a session is imported.
+type Options struct {
+	// Format is FormatAsciinema, FormatScript or FormatAuto (the default),
+	// which detects it.
+	Format string
+	// MaxOutputLines truncates longer outputs, keeping their first and last
+	// lines; DefaultMaxOutputLines when 0, no limit when negative.
+	MaxOutputLines int
+	// PromptPattern matches the recorded prompt; DefaultPromptPattern when nil.
+	PromptPattern *regexp.Regexp
+	// Prompt, when set, is shown as the terminal's prompt in place of the
+	// recorded one.
+	Prompt string
+}
+
+// Import returns the terminal actions of a recorded session: terminal-open,
+// then terminal-type and terminal-enter for each command and
+// terminal-set-output for its output. Recorded prompts are removed from the
+// commands.
+func Import(data []byte, options Options) ([]types.Action, error) {
+	format := options.Format
+	if format == "" || format == FormatAuto {
+		format = Detect(data)
+	}
+	var transcript string
+	switch format {
+	case FormatAsciinema:
+		var err error
+		if transcript, err = castOutput(data); err != nil {
+			return nil, err
+		}
+	case FormatScript:
+		transcript = typescriptOutput(data)
+	default:
+		return nil, fmt.Errorf("unknown session format %q (use auto, asciinema or script)", format)
+	}
+
+	pattern := options.PromptPattern
+	if pattern == nil {
+		pattern = DefaultPromptPattern
+	}
+	maxLines := options.MaxOutputLines
+	if maxLines == 0 {
+		maxLines = DefaultMaxOutputLines
+	}
+
+	actions := []types.Action{{Name: "terminal-open", Value: "1"}}
+	if options.Prompt != "" {
+		actions = append(actions, types.Action{Name: "terminal-set-prompt", Value: options.Prompt})
+	}
+	var command string
+	var output []string
+	commands := 0
+	flush := func() {
+		if command != "" {
+			actions = append(actions,
+				types.Action{Name: "terminal-type", Value: command},
+				types.Action{Name: "terminal-enter", Value: "1"},
+			)
+			if text := truncate(output, maxLines); text != "" {
+				actions = append(actions, types.Action{Name: "terminal-set-output", Value: text})
+			}
+			commands++
+		}
+		command, output = "", nil
+	}
+	for _, line := range screenLines(transcript) {
+		// lines are trimmed, so a prompt without a command lost its space
+		padded := line + " "
+		if prompt := pattern.FindString(padded); prompt != "" {
+			flush()
+			command = strings.TrimSpace(padded[len(prompt):])
+			if exitCommands[command] {
+				command = ""
+			}
+			continue
+		}
+		if command != "" {
+			output = append(output, line)
+		}
+	}
+	flush()
+
+	if commands == 0 {
+		return nil, fmt.Errorf("no commands found in the %s session; is the prompt matched by %s?", format, pattern)
+	}
+	return actions, nil
+}
+
+// Detect returns the format of a recorded session: asciinema when it starts
+// with a JSON header, script otherwise.
+func Detect(data []byte) string {
+	firstLine, _, _ := bytes.Cut(data, []byte("\n"))
+	var header struct {
+		Version int `json:"version"`
+	}
+	if json.Unmarshal(firstLine, &header) == nil && header.Version > 0 {
+		return FormatAsciinema
+	}
+	return FormatScript
+}
+
+// castOutput concatenates the output events of an asciinema v2 or v3
+// recording.
+func castOutput(data []byte) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	var header struct {
+		Version int `json:"version"`
+	}
+	if !scanner.Scan() || json.Unmarshal(scanner.Bytes(), &header) != nil {
+		return "", fmt.Errorf("invalid asciinema recording: the first line must be a JSON header")
+	}
+	if header.Version != 2 && header.Version != 3 {
+		return "", fmt.Errorf("unsupported asciinema recording version %d (use 2 or 3)", header.Version)
+	}
+
+	var out strings.Builder
+	for line := 2; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 || text[0] == '#' {
+			continue
+		}
+		var event []interface{}
+		if err := json.Unmarshal(text, &event); err != nil || len(event) != 3 {
+			return "", fmt.Errorf("invalid asciinema recording: line %d is not an event", line)
+		}
+		if code, _ := event[1].(string); code == "o" {
+			data, _ := event[2].(string)
+			out.WriteString(data)
+		}
+	}
+	return out.String(), scanner.Err()
+}
+
+// typescriptOutput strips the header and footer script(1) writes.
+func typescriptOutput(data []byte) string {
+	text := string(data)
+	if strings.HasPrefix(text, "Script started on ") {
+		if _, rest, ok := strings.Cut(text, "\n"); ok {
+			text = rest
+		}
+	}
+	if i := strings.LastIndex(text, "\nScript done on "); i >= 0 {
+		text = text[:i]
+	}
+	return text
+}
+
+// screenLines returns the lines a terminal shows for the transcript: escape
+// sequences are dropped and carriage returns, backspaces and erased lines
+// are applied, so progress bars and edited commands show their final state.
+func screenLines(transcript string) []string {
+	var lines []string
+	var line []rune
+	col := 0
+	put := func(r rune) {
+		if col < len(line) {
+			line[col] = r
+		} else {
+			line = append(line, r)
+		}
+		col++
+	}
+
+	runes := []rune(transcript)
+	for i := 0; i < len(runes); i++ {
+		switch r := runes[i]; {
+		case r == '\x1b' && i+1 < len(runes) && runes[i+1] == '[':
+			// CSI: parameters up to a final byte
+			j := i + 2
+			for j < len(runes) && (runes[j] < 0x40 || runes[j] > 0x7e) {
+				j++
+			}
+			if j < len(runes) && runes[j] == 'K' && col < len(line) {
+				line = line[:col]
+			}
+			i = j
+		case r == '\x1b' && i+1 < len(runes) && runes[i+1] == ']':
+			// OSC: up to BEL or ESC \
+			j := i + 2
+			for j < len(runes) && runes[j] != '\a' && !(runes[j] == '\x1b' && j+1 < len(runes) && runes[j+1] == '\\') {
+				j++
+			}
+			if j < len(runes) && runes[j] == '\x1b' {
+				j++
+			}
+			i = j
+		case r == '\x1b':
+			i++
+		case r == '\n':
+			lines = append(lines, strings.TrimRight(string(line), " \t"))
+			line, col = nil, 0
+		case r == '\r':
+			col = 0
+		case r == '\b':
+			if col > 0 {
+				col--
+			}
+		case r == '\t':
+			put(r)
+		case r < 0x20 || r == 0x7f:
+			// other control characters
+		default:
+			put(r)
+		}
+	}
+	if text := strings.TrimRight(string(line), " \t"); text != "" {
+		lines = append(lines, text)
+	}
+	return lines
+}
+
+// truncate joins output lines without leading and trailing blank lines,
+// keeping the first and last lines of outputs longer than maxLines.
+func truncate(lines []string, maxLines int) string {
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if maxLines > 0 && len(lines) > maxLines {
+		head := maxLines / 2
+		tail := maxLines - head - 1
+		omitted := len(lines) - head - tail
+		kept := append(append([]string{}, lines[:head]...), "... ("+strconv.Itoa(omitted)+" lines omitted)")
+		lines = append(kept, lines[len(lines)-tail:]...)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,68 @@
+package importer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/codevideo/codevideo-cli/types"
+)
+
+func TestImportAsciinema(t *testing.T) {
+	cast := `{"version": 2, "width": 80, "height": 24}
+[0.1, "o", "\u001b]0;user@host: ~/app\u0007user@host:~/app$ "]
+[0.5, "o", "npm ic\b \bnstall"]
+[0.6, "o", "\r\n"]
+[1.0, "o", "\u001b[32m⠋\u001b[0m installing\r\u001b[K"]
+[1.5, "o", "added 15 packages in 2s\r\n"]
+[1.6, "o", "user@host:~/app$ "]
+[1.7, "i", "l"]
+[1.8, "o", "ls\r\nindex.js  package.json\r\n"]
+[2.0, "o", "user@host:~/app$ exit\r\n"]
+`
+	actions, err := Import([]byte(cast), Options{Prompt: "$"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []types.Action{
+		{Name: "terminal-open", Value: "1"},
+		{Name: "terminal-set-prompt", Value: "$"},
+		{Name: "terminal-type", Value: "npm install"},
+		{Name: "terminal-enter", Value: "1"},
+		{Name: "terminal-set-output", Value: "added 15 packages in 2s"},
+		{Name: "terminal-type", Value: "ls"},
+		{Name: "terminal-enter", Value: "1"},
+		{Name: "terminal-set-output", Value: "index.js  package.json"},
+	}
+	if !reflect.DeepEqual(actions, want) {
+		t.Errorf("got\n%+v\nwant\n%+v", actions, want)
+	}
+}
+
+func TestImportScriptTruncatesOutput(t *testing.T) {
+	var typescript strings.Builder
+	typescript.WriteString("Script started on 2026-10-19 10:00:00+00:00 [TERM=\"xterm\"]\n")
+	typescript.WriteString("~/app % seq 30\r\n")
+	for i := 1; i <= 30; i++ {
+		typescript.WriteString(strings.Repeat("x", i) + "\r\n")
+	}
+	typescript.WriteString("~/app % \r\nScript done on 2026-10-19 10:00:05+00:00 [COMMAND_EXIT_CODE=\"0\"]\n")
+
+	actions, err := Import([]byte(typescript.String()), Options{MaxOutputLines: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(actions) != 4 || actions[1].Value != "seq 30" {
+		t.Fatalf("got %+v", actions)
+	}
+	want := "x\nxx\n... (26 lines omitted)\n" + strings.Repeat("x", 29) + "\n" + strings.Repeat("x", 30)
+	if actions[3].Value != want {
+		t.Errorf("output = %q, want %q", actions[3].Value, want)
+	}
+}
+
+func TestImportWithoutPrompts(t *testing.T) {
+	if _, err := Import([]byte("just output\n"), Options{Format: FormatScript}); err == nil || !strings.Contains(err.Error(), "no commands found") {
+		t.Errorf("error = %v", err)
+	}
+}
@@ -0,0 +1,166 @@
+package renderer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/codevideo/codevideo-cli/progress"
+)
+
+// Progress output formats accepted by --progress.
+const (
+	ProgressAuto  = "auto"  // tty when the output is a terminal, plain otherwise
+	ProgressTTY   = "tty"   // redrawn ANSI progress bar
+	ProgressPlain = "plain" // one human-readable line per change, no escape codes
+	ProgressJSON  = "json"  // one JSON object per line
+)
+
+// Messages receives human-readable status messages (e.g. "Detected project
+// type"). It is switched to stderr when JSON progress owns stdout.
+var Messages io.Writer = os.Stdout
+
+// Messagef writes a status message line to Messages.
+func Messagef(format string, args ...interface{}) {
+	fmt.Fprintf(Messages, format+"\n", args...)
+}
+
+// ProgressOutput is a resolved --progress/--progress-fd combination.
+type ProgressOutput struct {
+	Format string
+	File   *os.File
+}
+
+// ResolveProgressOutput validates the format and opens the output file
+// descriptor (1 for stdout, 2 for stderr, 3+ for a descriptor inherited from
+// the parent process). "auto" picks tty or plain based on the descriptor.
+func ResolveProgressOutput(format string, fd int) (*ProgressOutput, error) {
+	var file *os.File
+	switch fd {
+	case 1:
+		file = os.Stdout
+	case 2:
+		file = os.Stderr
+	default:
+		if fd < 1 {
+			return nil, fmt.Errorf("invalid progress file descriptor %d", fd)
+		}
+		file = os.NewFile(uintptr(fd), fmt.Sprintf("progress-fd-%d", fd))
+		if file == nil {
+			return nil, fmt.Errorf("invalid progress file descriptor %d", fd)
+		}
+		if _, err := file.Stat(); err != nil {
+			return nil, fmt.Errorf("progress file descriptor %d is not open: %w", fd, err)
+		}
+	}
+
+	switch format {
+	case "", ProgressAuto:
+		format = ProgressPlain
+		if IsTerminal(file) {
+			format = ProgressTTY
+		}
+	case ProgressTTY, ProgressPlain, ProgressJSON:
+	default:
+		return nil, fmt.Errorf("invalid progress format %q (expected auto, tty, plain or json)", format)
+	}
+	return &ProgressOutput{Format: format, File: file}, nil
+}
+
+// OwnsStdout reports whether machine-readable progress is written to stdout,
+// in which case logs and messages must go elsewhere.
+func (o *ProgressOutput) OwnsStdout() bool {
+	return o.Format == ProgressJSON && o.File == os.Stdout
+}
+
+// Subscriber returns a progress subscriber writing in the resolved format.
+func (o *ProgressOutput) Subscriber() progress.Subscriber {
+	switch o.Format {
+	case ProgressJSON:
+		return NewJSONSubscriber(o.File)
+	case ProgressPlain:
+		return NewPlainSubscriber(o.File)
+	default:
+		return NewTTYSubscriber(o.File)
+	}
+}
+
+// IsTerminal reports whether the file is an interactive terminal.
+func IsTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// NewTTYSubscriber redraws a single progress bar line on w.
+func NewTTYSubscriber(w io.Writer) progress.Subscriber {
+	return func(event progress.Event) {
+		// "\r" returns the cursor to the beginning and "\033[2K" clears the entire line
+		fmt.Fprintf(w, "\r\033[2K%s %s", ProgressBar(event.Percent, 32), event.Message)
+	}
+}
+
+// NewPlainSubscriber writes a line whenever the stage, message or whole
+// percentage changes, without escape codes, for non-TTY logs.
+func NewPlainSubscriber(w io.Writer) progress.Subscriber {
+	var mu sync.Mutex
+	var last string
+	return func(event progress.Event) {
+		line := fmt.Sprintf("[%3.0f%%] %s: %s", math.Floor(event.Percent), event.Stage, event.Message)
+		if event.TotalActions > 0 {
+			line += fmt.Sprintf(" (action %d/%d)", event.ActionIndex, event.TotalActions)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if line == last {
+			return
+		}
+		last = line
+		fmt.Fprintln(w, line)
+	}
+}
+
+// jsonProgressLine is the wire format of --progress=json.
+type jsonProgressLine struct {
+	JobUUID      string         `json:"jobUuid"`
+	Stage        progress.Stage `json:"stage"`
+	Percent      float64        `json:"percent"`
+	ActionIndex  int            `json:"actionIndex"`
+	TotalActions int            `json:"totalActions"`
+	Message      string         `json:"message"`
+	ElapsedMs    int64          `json:"elapsedMs"`
+	Time         time.Time      `json:"time"`
+}
+
+// NewJSONSubscriber writes one JSON object per event to w. Elapsed time is
+// measured from the first event of each job.
+func NewJSONSubscriber(w io.Writer) progress.Subscriber {
+	var mu sync.Mutex
+	started := make(map[string]time.Time)
+	encoder := json.NewEncoder(w)
+	return func(event progress.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		start, ok := started[event.JobUUID]
+		if !ok {
+			start = event.Time
+			started[event.JobUUID] = start
+		}
+		encoder.Encode(jsonProgressLine{
+			JobUUID:      event.JobUUID,
+			Stage:        event.Stage,
+			Percent:      math.Round(event.Percent*10) / 10,
+			ActionIndex:  event.ActionIndex,
+			TotalActions: event.TotalActions,
+			Message:      event.Message,
+			ElapsedMs:    event.Time.Sub(start).Milliseconds(),
+			Time:         event.Time,
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package renderer
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codevideo/codevideo-cli/progress"
+)
+
+func TestJSONSubscriberWritesOneObjectPerLine(t *testing.T) {
+	var buf bytes.Buffer
+	subscriber := NewJSONSubscriber(&buf)
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	subscriber(progress.Event{JobUUID: "job", Stage: progress.StageAudio, Percent: 2.345, ActionIndex: 1, TotalActions: 4, Message: "audio", Time: start})
+	subscriber(progress.Event{JobUUID: "job", Stage: progress.StageRecording, Percent: 50, ActionIndex: 2, TotalActions: 4, Message: "rec", Time: start.Add(1500 * time.Millisecond)})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines: %q", len(lines), buf.String())
+	}
+	var second map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[1]), &second); err != nil {
+		t.Fatal(err)
+	}
+	if second["stage"] != "recording" || second["elapsedMs"] != float64(1500) || second["actionIndex"] != float64(2) || second["totalActions"] != float64(4) {
+		t.Errorf("unexpected line: %s", lines[1])
+	}
+	if !strings.Contains(lines[0], `"percent":2.3`) {
+		t.Errorf("percent should be rounded to one decimal: %s", lines[0])
+	}
+}
+
+func TestPlainSubscriberSkipsUnchangedLines(t *testing.T) {
+	var buf bytes.Buffer
+	subscriber := NewPlainSubscriber(&buf)
+	for _, percent := range []float64{10.1, 10.4, 10.9, 11} {
+		subscriber(progress.Event{Stage: progress.StageRecording, Percent: percent, Message: "Rendering video..."})
+	}
+	got := buf.String()
+	if strings.Contains(got, "\033") {
+		t.Errorf("plain output must not contain escape codes: %q", got)
+	}
+	if want := "[ 10%] recording: Rendering video...\n[ 11%] recording: Rendering video...\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestResolveProgressOutput(t *testing.T) {
+	output, err := ResolveProgressOutput(ProgressJSON, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output.File != os.Stdout || !output.OwnsStdout() {
+		t.Errorf("json on fd 1 should own stdout")
+	}
+	if output, err := ResolveProgressOutput(ProgressJSON, 2); err != nil || output.OwnsStdout() {
+		t.Errorf("json on fd 2 should not own stdout (%v)", err)
+	}
+	if _, err := ResolveProgressOutput("fancy", 1); err == nil {
+		t.Error("expected invalid format error")
+	}
+	if _, err := ResolveProgressOutput(ProgressPlain, 987); err == nil {
+		t.Error("expected closed descriptor error")
+	}
+}
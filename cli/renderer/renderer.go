@@ -2,10 +2,7 @@ package renderer
 
 import (
 	"fmt"
-	"os"
 	"strings"
-
-	slack "github.com/codevideo/go-utils/slack"
 )
 
 // ProgressBar renders a progress bar in the CLI based on a percentage value
@@ -40,10 +37,6 @@ func ProgressBar(percentage float64, totalWidth int) string {
 	// Format the percentage (right-aligned)
 	percentStr := fmt.Sprintf(" %3.0f%%", percentage)
 
-	// Send Slack notification of progress
-	environment := strings.ToUpper(os.Getenv("ENVIRONMENT"))
-	slack.SendSlackMessage(fmt.Sprintf("%s: CodeVideo progress: %.0f%% complete", environment, percentage))
-
 	return bar + percentStr
 }
 
@@ -74,24 +67,3 @@ func MultiProgressBar(percentages []float64, labels []string, totalWidth int) []
 
 	return result
 }
-
-// RenderProgressToConsole prints the progress bar to the console
-// This function can be called repeatedly to update the progress display
-func RenderProgressToConsole(percentage float64, message string) {
-	bar := ProgressBar(percentage, 32)
-	// "\r" returns the cursor to the beginning and "\033[2K" clears the entire line
-	fmt.Printf("\r\033[2K%s %s", bar, message)
-}
-
-// RenderMultiProgressToConsole prints multiple progress bars to the console
-func RenderMultiProgressToConsole(percentages []float64, labels []string) {
-	// Clear the terminal lines first
-	for i := 0; i < len(percentages); i++ {
-		fmt.Print("\033[1A\033[K") // Move up one line and clear it
-	}
-
-	bars := MultiProgressBar(percentages, labels, 32)
-	for _, bar := range bars {
-		fmt.Println(bar)
-	}
-}
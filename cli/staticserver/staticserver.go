@@ -12,6 +12,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 	"time"
 
 	"github.com/codevideo/codevideo-cli/constants"
@@ -19,8 +20,12 @@ import (
 
 // Server serves static files from the embedded public folder.
 type Server struct {
+	// Port and ManifestPort are the requested ports (0 picks a free port) and
+	// the bound ones once started.
 	Port             int
+	ManifestPort     int
 	serverURL        string
+	manifestURL      string
 	startupTimeout   time.Duration
 	httpServer       *http.Server
 	manifestServer   *http.Server
@@ -31,14 +36,25 @@ type Server struct {
 //go:embed public
 var public embed.FS
 
-// Start creates and starts a new HTTP server that serves the embedded public folder.
+// Endpoints are the base URLs the Puppeteer runner loads the player and the
+// manifests from.
+type Endpoints struct {
+	StaticURL string
+	// ManifestURL is empty when the manifest server isn't running.
+	ManifestURL string
+}
+
+// Start creates and starts a new HTTP server that serves the embedded public
+// folder, on the configured ports or free loopback ports, so any number of
+// processes can render side by side.
 func Start(ctx context.Context) (*Server, error) {
 
 	// Create a new server instance.
-	srv, err := NewServer(constants.DEFAULT_GATSBY_PORT, constants.DEFAULT_SERVER_TIMEOUT)
+	srv, err := NewServer(constants.StaticServerPort(), constants.DEFAULT_SERVER_TIMEOUT)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create server: %w", err)
 	}
+	srv.ManifestPort = constants.ManifestServerPort()
 
 	// Start the embedded HTTP server.
 	if err := srv.StartServer(ctx); err != nil {
@@ -55,12 +71,11 @@ func Start(ctx context.Context) (*Server, error) {
 	return srv, nil
 }
 
-// NewServer creates a new Server instance.
-func NewServer(startPort int, timeout time.Duration) (*Server, error) {
-	serverURL := fmt.Sprintf("http://localhost:%d", startPort)
+// NewServer creates a new Server instance for the given static port (0 picks
+// a free port when started).
+func NewServer(port int, timeout time.Duration) (*Server, error) {
 	return &Server{
-		Port:           startPort,
-		serverURL:      serverURL,
+		Port:           port,
 		startupTimeout: timeout,
 	}, nil
 }
@@ -70,6 +85,11 @@ func (s *Server) GetURL() string {
 	return s.serverURL
 }
 
+// Endpoints returns the URLs of the running servers.
+func (s *Server) Endpoints() Endpoints {
+	return Endpoints{StaticURL: s.serverURL, ManifestURL: s.manifestURL}
+}
+
 // GetURLWithParams returns the URL with query parameters (e.g. for video recording pages).
 func (s *Server) GetURLWithParams(uuid string) string {
 	return fmt.Sprintf("%s/v3?uuid=%s", s.serverURL, uuid)
@@ -89,9 +109,10 @@ func (s *Server) StartServer(ctx context.Context) error {
 	// Wrap the fileServer with CORS middleware.
 	staticHandler := corsMiddleware(fileServer)
 
-	// Set up the HTTP server for static assets.
+	// Set up the HTTP server for static assets. Both servers only listen on
+	// loopback; the browser recording the video is the only client.
 	s.httpServer = &http.Server{
-		Addr:    fmt.Sprintf(":%d", s.Port),
+		Addr:    loopbackAddr(s.Port),
 		Handler: staticHandler,
 	}
 
@@ -99,7 +120,7 @@ func (s *Server) StartServer(ctx context.Context) error {
 	manifestMux := http.NewServeMux()
 	manifestMux.HandleFunc("/get-manifest-v3", getManifestV3Handler)
 	s.manifestServer = &http.Server{
-		Addr:    fmt.Sprintf(":%d", constants.DEFAULT_MANIFEST_SERVER_PORT),
+		Addr:    loopbackAddr(s.ManifestPort),
 		Handler: corsMiddleware(manifestMux),
 	}
 
@@ -108,30 +129,34 @@ func (s *Server) StartServer(ctx context.Context) error {
 		return fmt.Errorf("static server port %d is unavailable: %w", s.Port, err)
 	}
 	s.staticListener = staticListener
+	s.Port = staticListener.Addr().(*net.TCPAddr).Port
+	s.serverURL = fmt.Sprintf("http://%s", staticListener.Addr())
 
 	manifestListener, manifestErr := net.Listen("tcp", s.manifestServer.Addr)
 	if manifestErr != nil {
 		log.Printf(
 			"Manifest server port %d is unavailable; the Puppeteer runner will load the manifest directly: %v",
-			constants.DEFAULT_MANIFEST_SERVER_PORT,
+			s.ManifestPort,
 			manifestErr,
 		)
 		s.manifestServer = nil
 	} else {
 		s.manifestListener = manifestListener
+		s.ManifestPort = manifestListener.Addr().(*net.TCPAddr).Port
+		s.manifestURL = fmt.Sprintf("http://%s", manifestListener.Addr())
 	}
 
 	// Start the servers in separate goroutines after both ports are reserved.
 	go func() {
 		if err := s.httpServer.Serve(staticListener); err != nil && err != http.ErrServerClosed {
-			fmt.Printf("HTTP server error: %v\n", err)
+			log.Errorf("HTTP server error: %v", err)
 		}
 	}()
 
 	if s.manifestServer != nil {
 		go func() {
 			if err := s.manifestServer.Serve(manifestListener); err != nil && err != http.ErrServerClosed {
-				fmt.Printf("Manifest server error: %v\n", err)
+				log.Errorf("Manifest server error: %v", err)
 			}
 		}()
 	}
@@ -195,6 +220,10 @@ func (s *Server) waitForServerReady(ctx context.Context) error {
 	}
 }
 
+func loopbackAddr(port int) string {
+	return net.JoinHostPort("127.0.0.1", strconv.Itoa(port))
+}
+
 func getManifestV3Handler(w http.ResponseWriter, r *http.Request) {
 	// Get the UUID from query parameters
 	uuid := r.URL.Query().Get("uuid")
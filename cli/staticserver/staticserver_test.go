@@ -33,3 +33,29 @@ func TestStartServerReportsOccupiedStaticPort(t *testing.T) {
 		t.Fatalf("expected occupied port error, got %v", err)
 	}
 }
+
+func TestStartPicksFreeLoopbackPorts(t *testing.T) {
+	t.Setenv("CODEVIDEO_STATIC_PORT", "")
+	t.Setenv("CODEVIDEO_MANIFEST_PORT", "")
+
+	first, err := Start(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer first.Stop()
+	second, err := Start(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer second.Stop()
+
+	a, b := first.Endpoints(), second.Endpoints()
+	if a.StaticURL == b.StaticURL || a.ManifestURL == b.ManifestURL {
+		t.Fatalf("concurrent servers share endpoints: %+v and %+v", a, b)
+	}
+	for _, url := range []string{a.StaticURL, a.ManifestURL, b.StaticURL, b.ManifestURL} {
+		if !strings.HasPrefix(url, "http://127.0.0.1:") {
+			t.Errorf("endpoint %s should be bound to loopback", url)
+		}
+	}
+}
@@ -10,31 +10,23 @@ import (
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/codevideo/codevideo-cli/metrics"
 )
 
+// bucket receives all uploads, under the "codevideo/" prefix.
+const bucket = "fullstackcraft"
+
 // UploadFileToS3 uploads a file buffer to S3 and returns the URL.
 // It expects the environment variables CODEVIDEO_S3_KEY_ID and CODEVIDEO_S3_SECRET
 // to be set for authentication.
 func UploadFileToS3(ctx context.Context, buffer []byte, path string, filename string) (string, error) {
-	accessKeyID := os.Getenv("CODEVIDEO_S3_KEY_ID")
-	secretAccessKey := os.Getenv("CODEVIDEO_S3_SECRET")
-	if accessKeyID == "" || secretAccessKey == "" {
-		return "", fmt.Errorf("S3 credentials are not set")
-	}
-
-	// Load AWS configuration
-	cfg, err := config.LoadDefaultConfig(ctx,
-		config.WithRegion("us-east-1"),
-		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(accessKeyID, secretAccessKey, "")),
-	)
+	s3Client, err := newS3Client(ctx)
 	if err != nil {
-		return "", fmt.Errorf("failed to load configuration: %w", err)
+		return "", err
 	}
 
-	s3Client := s3.NewFromConfig(cfg)
-
-	// Define the bucket and key for the upload.
-	bucket := "fullstackcraft"
+	// Define the key for the upload.
 	key := fmt.Sprintf("codevideo/%s/%s", path, filename)
 
 	// Perform the PutObject request.
@@ -46,8 +38,39 @@ func UploadFileToS3(ctx context.Context, buffer []byte, path string, filename st
 	if err != nil {
 		return "", fmt.Errorf("error uploading object: %w", err)
 	}
+	metrics.AddUploadedBytes(len(buffer))
 
 	// Return the public URL for the uploaded file.
 	url := fmt.Sprintf("https://fullstackcraft.s3.us-east-1.amazonaws.com/%s", key)
 	return url, nil
 }
+
+// CheckS3 verifies that the bucket is reachable with the configured credentials.
+func CheckS3(ctx context.Context) error {
+	s3Client, err := newS3Client(ctx)
+	if err != nil {
+		return err
+	}
+	if _, err := s3Client.HeadBucket(ctx, &s3.HeadBucketInput{Bucket: aws.String(bucket)}); err != nil {
+		return fmt.Errorf("bucket %s is not reachable: %w", bucket, err)
+	}
+	return nil
+}
+
+func newS3Client(ctx context.Context) (*s3.Client, error) {
+	accessKeyID := os.Getenv("CODEVIDEO_S3_KEY_ID")
+	secretAccessKey := os.Getenv("CODEVIDEO_S3_SECRET")
+	if accessKeyID == "" || secretAccessKey == "" {
+		return nil, fmt.Errorf("S3 credentials are not set")
+	}
+
+	// Load AWS configuration
+	cfg, err := config.LoadDefaultConfig(ctx,
+		config.WithRegion("us-east-1"),
+		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(accessKeyID, secretAccessKey, "")),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	return s3.NewFromConfig(cfg), nil
+}
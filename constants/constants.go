@@ -4,17 +4,22 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 )
 
 const (
-	LEGACY_WORK_FOLDER           = "../tmp/v3"
-	NODE_SCRIPT_NAME             = "puppeteer-runner/recordVideoV3.js"
-	TOKEN_DECREMENT_AMOUNT       = 10
-	MAX_CONCURRENT_JOBS          = 2 // default for an 8 GB server serving both staging and prod; override with CODEVIDEO_MAX_CONCURRENT_JOBS
-	DEFAULT_MANIFEST_SERVER_PORT = 7000
-	DEFAULT_GATSBY_PORT          = 7001
-	DEFAULT_SERVER_TIMEOUT       = time.Second * 5
+	LEGACY_WORK_FOLDER       = "../tmp/v3"
+	NODE_SCRIPT_NAME         = "puppeteer-runner/recordVideoV3.js"
+	TOKENS_PER_MINUTE        = 10 // tokens charged per started minute of estimated video length
+	MAX_CONCURRENT_JOBS      = 2  // default for an 8 GB server serving both staging and prod; override with CODEVIDEO_MAX_CONCURRENT_JOBS
+	MAX_JOBS_PER_USER        = 1  // concurrent jobs of a single user; override with CODEVIDEO_MAX_JOBS_PER_USER
+	DEFAULT_SERVER_TIMEOUT   = time.Second * 5
+	DEFAULT_API_ADDR         = "127.0.0.1:8080" // serve-mode status API; override with CODEVIDEO_API_ADDR
+	SLACK_PROGRESS_MILESTONE = 25               // percent between Slack progress notifications
+	DEFAULT_STALL_TIMEOUT    = 3 * time.Minute  // max time without runner progress; override with CODEVIDEO_STALL_TIMEOUT
+	RENDER_TIMEOUT_FACTOR    = 3.0              // max runtime as a multiple of the estimate; override with CODEVIDEO_RENDER_TIMEOUT_FACTOR
+	DEFAULT_DRAIN_TIMEOUT    = 5 * time.Minute  // time in-flight jobs get to finish on shutdown; override with CODEVIDEO_DRAIN_TIMEOUT
 )
 
 func executableDir() string {
@@ -37,6 +42,22 @@ func absoluteEnvPath(name string) string {
 	return absolute
 }
 
+// EnvFile returns the dotenv file loaded at startup: CODEVIDEO_ENV_FILE when
+// set, otherwise .env next to the executable, falling back to .env.example
+// for source installs. It returns "" when none of them exists.
+func EnvFile() string {
+	if configured := os.Getenv("CODEVIDEO_ENV_FILE"); configured != "" {
+		return configured
+	}
+	for _, name := range []string{".env", ".env.example"} {
+		candidate := filepath.Join(executableDir(), name)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
+		}
+	}
+	return ""
+}
+
 // WorkFolder returns the base directory for transient render state. The
 // executable-relative location remains the default for standalone binaries;
 // the npm launcher supplies CODEVIDEO_WORK_DIR so node_modules stays read-only.
@@ -84,3 +105,142 @@ func MaxConcurrentJobs() int {
 	}
 	return MAX_CONCURRENT_JOBS
 }
+
+// MaxJobsPerUser returns how many of one user's jobs may run at the same time,
+// overridable via CODEVIDEO_MAX_JOBS_PER_USER (0 disables the cap).
+func MaxJobsPerUser() int {
+	if v := os.Getenv("CODEVIDEO_MAX_JOBS_PER_USER"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			return n
+		}
+	}
+	return MAX_JOBS_PER_USER
+}
+
+// UserDirectory returns where serve mode looks up users, their email and
+// tokens, from CODEVIDEO_USER_DIRECTORY: "clerk" (default), "file" for a
+// local users file on self-hosted deployments, or "none".
+func UserDirectory() string {
+	if v := strings.ToLower(os.Getenv("CODEVIDEO_USER_DIRECTORY")); v != "" {
+		return v
+	}
+	return "clerk"
+}
+
+// UserDirectoryFile returns the users file of the "file" user directory,
+// overridable via CODEVIDEO_USERS_FILE.
+func UserDirectoryFile() string {
+	if path := absoluteEnvPath("CODEVIDEO_USERS_FILE"); path != "" {
+		return path
+	}
+	return filepath.Join(WorkFolder(), "users.json")
+}
+
+// LedgerFile returns the append-only token usage ledger, overridable via
+// CODEVIDEO_LEDGER_FILE.
+func LedgerFile() string {
+	if path := absoluteEnvPath("CODEVIDEO_LEDGER_FILE"); path != "" {
+		return path
+	}
+	return filepath.Join(WorkFolder(), "ledger.jsonl")
+}
+
+// APIAddr returns the listen address of the serve-mode API from
+// CODEVIDEO_API_ADDR (e.g. "0.0.0.0:8080" inside a container), or
+// DEFAULT_API_ADDR when it's unset. Set but empty disables the API.
+func APIAddr() string {
+	if v, ok := os.LookupEnv("CODEVIDEO_API_ADDR"); ok {
+		return v
+	}
+	return DEFAULT_API_ADDR
+}
+
+// StaticServerPort returns the port of the static player server from
+// CODEVIDEO_STATIC_PORT; 0 (the default) picks a free port.
+func StaticServerPort() int {
+	return portFromEnv("CODEVIDEO_STATIC_PORT")
+}
+
+// ManifestServerPort returns the port of the manifest server from
+// CODEVIDEO_MANIFEST_PORT; 0 (the default) picks a free port.
+func ManifestServerPort() int {
+	return portFromEnv("CODEVIDEO_MANIFEST_PORT")
+}
+
+func portFromEnv(name string) int {
+	if v := os.Getenv(name); v != "" {
+		if port, err := strconv.Atoi(v); err == nil && port > 0 && port < 65536 {
+			return port
+		}
+	}
+	return 0
+}
+
+// MetricsAddr returns the listen address of the serve-mode Prometheus
+// endpoint from CODEVIDEO_METRICS_ADDR; empty (the default) disables it.
+func MetricsAddr() string {
+	return os.Getenv("CODEVIDEO_METRICS_ADDR")
+}
+
+// SlackProgressEnabled reports whether progress milestones are posted to Slack.
+// CODEVIDEO_SLACK_PROGRESS ("true"/"false") overrides the mode's default.
+func SlackProgressEnabled(defaultEnabled bool) bool {
+	if v := os.Getenv("CODEVIDEO_SLACK_PROGRESS"); v != "" {
+		if enabled, err := strconv.ParseBool(v); err == nil {
+			return enabled
+		}
+	}
+	return defaultEnabled
+}
+
+// StallTimeout returns how long a recording may go without a progress event
+// before it is killed, overridable via CODEVIDEO_STALL_TIMEOUT (e.g. "5m").
+func StallTimeout() time.Duration {
+	if v := os.Getenv("CODEVIDEO_STALL_TIMEOUT"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			return d
+		}
+	}
+	return DEFAULT_STALL_TIMEOUT
+}
+
+// DrainTimeout returns how long in-flight jobs may keep running after a
+// shutdown signal before they are cancelled, overridable via CODEVIDEO_DRAIN_TIMEOUT (e.g. "10m").
+func DrainTimeout() time.Duration {
+	if v := os.Getenv("CODEVIDEO_DRAIN_TIMEOUT"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			return d
+		}
+	}
+	return DEFAULT_DRAIN_TIMEOUT
+}
+
+// RenderTimeoutFactor returns the multiple of the estimated recording length
+// after which a recording is killed, overridable via CODEVIDEO_RENDER_TIMEOUT_FACTOR.
+func RenderTimeoutFactor() float64 {
+	if v := os.Getenv("CODEVIDEO_RENDER_TIMEOUT_FACTOR"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil && f > 0 {
+			return f
+		}
+	}
+	return RENDER_TIMEOUT_FACTOR
+}
+
+// TTSProvider returns the speech provider from CODEVIDEO_TTS_PROVIDER:
+// "elevenlabs" (the default, cloud + S3) or "kokoro" (self-hosted codevideo-tts).
+func TTSProvider() string {
+	provider := strings.ToLower(strings.TrimSpace(os.Getenv("CODEVIDEO_TTS_PROVIDER")))
+	if provider == "" {
+		return "elevenlabs"
+	}
+	return provider
+}
+
+// TTSServiceURL returns the base URL of the self-hosted TTS service from
+// TTS_SERVICE_URL (default http://localhost:3000).
+func TTSServiceURL() string {
+	if base := strings.TrimRight(os.Getenv("TTS_SERVICE_URL"), "/"); base != "" {
+		return base
+	}
+	return "http://localhost:3000"
+}
@@ -27,3 +27,14 @@ func TestRuntimePathOverrides(t *testing.T) {
 		}
 	}
 }
+
+func TestAPIAddrCanBeDisabled(t *testing.T) {
+	t.Setenv("CODEVIDEO_API_ADDR", "0.0.0.0:9000")
+	if got := APIAddr(); got != "0.0.0.0:9000" {
+		t.Errorf("APIAddr() = %q", got)
+	}
+	t.Setenv("CODEVIDEO_API_ADDR", "")
+	if got := APIAddr(); got != "" {
+		t.Errorf("APIAddr() = %q, want it disabled", got)
+	}
+}
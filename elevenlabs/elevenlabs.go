@@ -2,6 +2,7 @@ package elevenlabs
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -124,3 +125,30 @@ func GetAudioArrayBufferElevenLabs(textToSpeak, ttsApiKey, ttsVoiceId string) ([
 
 	return audioData, nil
 }
+
+// CheckReachable verifies that the ElevenLabs API answers and accepts the API key.
+func CheckReachable(ctx context.Context, ttsApiKey string) error {
+	if ttsApiKey == "" {
+		return fmt.Errorf("ELEVEN_LABS_API_KEY is not set")
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://api.elevenlabs.io/v1/models", nil)
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("xi-api-key", ttsApiKey)
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("ElevenLabs API is not reachable: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized:
+		return fmt.Errorf("ElevenLabs rejected the API key")
+	case resp.StatusCode >= 500:
+		return fmt.Errorf("ElevenLabs API returned %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,260 @@
+package gitlesson
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/codevideo/codevideo-cli/types"
+)
+
+// maxDiffCells bounds the line diff's table; larger changes are replaced as
+// one block.
+const maxDiffCells = 4_000_000
+
+// hunk replaces the old lines [start, start+removed) with added.
+type hunk struct {
+	start   int
+	removed []string
+	added   []string
+}
+
+// diffLines returns the hunks turning old into new, from a longest common
+// subsequence of lines.
+func diffLines(old []string, new []string) []hunk {
+	// common prefix and suffix
+	prefix := 0
+	for prefix < len(old) && prefix < len(new) && old[prefix] == new[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(old)-prefix && suffix < len(new)-prefix && old[len(old)-1-suffix] == new[len(new)-1-suffix] {
+		suffix++
+	}
+	a, b := old[prefix:len(old)-suffix], new[prefix:len(new)-suffix]
+	if len(a) == 0 && len(b) == 0 {
+		return nil
+	}
+	if len(a)*len(b) > maxDiffCells || len(a) == 0 || len(b) == 0 {
+		return []hunk{{start: prefix, removed: a, added: b}}
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var hunks []hunk
+	var current *hunk
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		if i < len(a) && j < len(b) && a[i] == b[j] {
+			current = nil
+			i++
+			j++
+			continue
+		}
+		if current == nil {
+			hunks = append(hunks, hunk{start: prefix + i})
+			current = &hunks[len(hunks)-1]
+		}
+		if j < len(b) && (i == len(a) || lcs[i][j+1] >= lcs[i+1][j]) {
+			current.added = append(current.added, b[j])
+			j++
+		} else {
+			current.removed = append(current.removed, a[i])
+			i++
+		}
+	}
+	return hunks
+}
+
+// editor mirrors the player's editor for one open file: its lines and
+// caret. Columns count UTF-16 code units, as the player does.
+type editor struct {
+	lines []string
+	row   int
+	col   int
+}
+
+func newEditor(content string) *editor {
+	return &editor{lines: strings.Split(content, "\n")}
+}
+
+func (e *editor) content() string {
+	return strings.Join(e.lines, "\n")
+}
+
+// width is the length of s in the player's units.
+func width(s string) int {
+	return len(utf16.Encode([]rune(s)))
+}
+
+// cut splits s after n UTF-16 code units.
+func cut(s string, n int) (string, string) {
+	units := utf16.Encode([]rune(s))
+	if n > len(units) {
+		n = len(units)
+	}
+	return string(utf16.Decode(units[:n])), string(utf16.Decode(units[n:]))
+}
+
+// edit turns the editor's content into content with caret moves,
+// backspaces and typing, one hunk at a time, and returns the actions.
+func (e *editor) edit(content string) []types.Action {
+	var actions []types.Action
+	offset := 0
+	for _, h := range diffLines(e.lines, strings.Split(content, "\n")) {
+		row := h.start + offset
+		actions = append(actions, e.apply(row, h.removed, h.added)...)
+		offset += len(h.added) - len(h.removed)
+	}
+	return actions
+}
+
+// apply replaces the removed lines starting at row with added.
+func (e *editor) apply(row int, removed []string, added []string) []types.Action {
+	var actions []types.Action
+	if text := strings.Join(removed, "\n"); strings.TrimSpace(text) != "" {
+		actions = append(actions, action("editor-highlight-code", text))
+	}
+	chars := 0
+	for _, line := range removed {
+		chars += width(line)
+	}
+
+	switch {
+	case len(removed) > 0 && len(added) > 0:
+		// empty the removed lines into one blank line, then type over it
+		actions = append(actions, e.moveTo(row+len(removed)-1, true)...)
+		actions = append(actions, e.backspace(chars+len(removed)-1)...)
+		actions = append(actions, e.typeLines(added)...)
+	case len(removed) > 0 && row+len(removed) < len(e.lines):
+		// delete forwards from the start of the next line
+		actions = append(actions, e.moveTo(row+len(removed), false)...)
+		actions = append(actions, e.backspace(chars+len(removed))...)
+	case len(removed) > 0 && row > 0:
+		// the last lines: delete back to the end of the line before
+		actions = append(actions, e.moveTo(row+len(removed)-1, true)...)
+		actions = append(actions, e.backspace(chars+len(removed))...)
+	case len(removed) > 0:
+		actions = append(actions, e.moveTo(row+len(removed)-1, true)...)
+		actions = append(actions, e.backspace(chars+len(removed)-1)...)
+	case row < len(e.lines):
+		// insert above the line at row
+		actions = append(actions, e.moveTo(row, false)...)
+		actions = append(actions, e.typeLines(append(append([]string{}, added...), ""))...)
+	default:
+		// append after the last line
+		actions = append(actions, e.moveTo(len(e.lines)-1, true)...)
+		actions = append(actions, e.typeLines(append([]string{""}, added...))...)
+	}
+	return actions
+}
+
+// moveTo moves the caret to the start or end of row.
+func (e *editor) moveTo(row int, end bool) []types.Action {
+	var actions []types.Action
+	if row > e.row {
+		actions = append(actions, action("editor-arrow-down", strconv.Itoa(row-e.row)))
+	} else if row < e.row {
+		actions = append(actions, action("editor-arrow-up", strconv.Itoa(e.row-row)))
+	}
+	e.row = row
+
+	target := 0
+	if end {
+		target = width(e.lines[row])
+	}
+	if e.col == target {
+		return actions
+	}
+	// the player leaves the column alone on vertical moves, and Command-Right
+	// doesn't move a caret past the end of the line
+	if e.col > target {
+		actions = append(actions, action("editor-command-left", "1"))
+		e.col = 0
+	}
+	if e.col < target {
+		actions = append(actions, action("editor-command-right", "1"))
+		e.col = target
+	}
+	return actions
+}
+
+// typeLines types lines at the caret: editor-type for text and editor-enter
+// between lines.
+func (e *editor) typeLines(lines []string) []types.Action {
+	var actions []types.Action
+	enters := 0
+	for i, line := range lines {
+		if i > 0 {
+			enters++
+		}
+		if line == "" {
+			continue
+		}
+		if enters > 0 {
+			actions = append(actions, action("editor-enter", strconv.Itoa(enters)))
+			e.enter(enters)
+			enters = 0
+		}
+		actions = append(actions, action("editor-type", line))
+		e.typeText(line)
+	}
+	if enters > 0 {
+		actions = append(actions, action("editor-enter", strconv.Itoa(enters)))
+		e.enter(enters)
+	}
+	return actions
+}
+
+func (e *editor) typeText(text string) {
+	before, after := cut(e.lines[e.row], e.col)
+	e.lines[e.row] = before + text + after
+	e.col += width(text)
+}
+
+func (e *editor) enter(times int) {
+	for range times {
+		before, after := cut(e.lines[e.row], e.col)
+		e.lines[e.row] = before
+		e.lines = append(e.lines[:e.row+1], append([]string{after}, e.lines[e.row+1:]...)...)
+		e.row++
+		e.col = 0
+	}
+}
+
+func (e *editor) backspace(times int) []types.Action {
+	if times == 0 {
+		return nil
+	}
+	for range times {
+		if e.col > 0 {
+			before, after := cut(e.lines[e.row], e.col)
+			before, _ = cut(before, e.col-1)
+			e.lines[e.row] = before + after
+			e.col--
+		} else if e.row > 0 {
+			e.col = width(e.lines[e.row-1])
+			e.lines[e.row-1] += e.lines[e.row]
+			e.lines = append(e.lines[:e.row], e.lines[e.row+1:]...)
+			e.row--
+		}
+	}
+	return []types.Action{action("editor-backspace", strconv.Itoa(times))}
+}
+
+func action(name string, value string) types.Action {
+	return types.Action{Name: name, Value: value}
+}
@@ -0,0 +1,131 @@
+package gitlesson
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// commit is a commit of the walked range.
+type commit struct {
+	hash    string
+	parent  string // the first parent; empty for a root commit
+	subject string
+	body    string
+}
+
+// change is one file a commit added, modified, deleted or renamed.
+type change struct {
+	status  byte // 'A', 'M', 'D' or 'R'
+	path    string
+	oldPath string // for renames
+}
+
+// repository runs git in a working tree or bare repository.
+type repository struct {
+	dir string
+}
+
+func (r repository) git(ctx context.Context, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", r.dir}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("git %s: %s", args[0], message)
+		}
+		return nil, fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return output, nil
+}
+
+// commits returns the commits of revisionRange (e.g. v1.0..v2.0) oldest
+// first, following first parents through merges.
+func (r repository) commits(ctx context.Context, revisionRange string) ([]commit, error) {
+	output, err := r.git(ctx, "log", "--reverse", "--first-parent", "--format=%H%x1f%P%x1f%s%x1f%b%x1e", revisionRange, "--")
+	if err != nil {
+		return nil, err
+	}
+	var commits []commit
+	for _, record := range strings.Split(string(output), "\x1e") {
+		fields := strings.Split(strings.TrimLeft(record, "\n"), "\x1f")
+		if len(fields) != 4 {
+			continue
+		}
+		parent, _, _ := strings.Cut(fields[1], " ")
+		commits = append(commits, commit{hash: fields[0], parent: parent, subject: fields[2], body: strings.TrimSpace(fields[3])})
+	}
+	return commits, nil
+}
+
+// changes returns the files c changed relative to its first parent.
+func (r repository) changes(ctx context.Context, c commit) ([]change, error) {
+	args := []string{"diff-tree", "-r", "-M", "--name-status", "-z", "--no-commit-id"}
+	if c.parent == "" {
+		args = append(args, "--root", c.hash)
+	} else {
+		args = append(args, c.parent, c.hash)
+	}
+	output, err := r.git(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []change
+	fields := strings.Split(strings.TrimSuffix(string(output), "\x00"), "\x00")
+	for i := 0; i < len(fields); i++ {
+		if fields[i] == "" {
+			continue
+		}
+		status := fields[i][0]
+		switch status {
+		case 'R', 'C':
+			if i+2 >= len(fields) {
+				return nil, fmt.Errorf("unexpected git diff-tree output for %s", c.hash)
+			}
+			if status == 'C' {
+				changes = append(changes, change{status: 'A', path: fields[i+2]})
+			} else {
+				changes = append(changes, change{status: 'R', oldPath: fields[i+1], path: fields[i+2]})
+			}
+			i += 2
+		default:
+			if i+1 >= len(fields) {
+				return nil, fmt.Errorf("unexpected git diff-tree output for %s", c.hash)
+			}
+			if status == 'T' {
+				status = 'M'
+			}
+			changes = append(changes, change{status: status, path: fields[i+1]})
+			i++
+		}
+	}
+	return changes, nil
+}
+
+// file returns the content of path at revision.
+func (r repository) file(ctx context.Context, revision string, path string) ([]byte, error) {
+	return r.git(ctx, "cat-file", "blob", revision+":"+path)
+}
+
+// tags maps commit hashes to the tags pointing at them.
+func (r repository) tags(ctx context.Context) (map[string][]string, error) {
+	output, err := r.git(ctx, "for-each-ref", "refs/tags", "--sort=creatordate", "--format=%(objectname) %(*objectname) %(refname:short)")
+	if err != nil {
+		return nil, err
+	}
+	tags := map[string][]string{}
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		fields := strings.Fields(line)
+		switch len(fields) {
+		case 2: // lightweight tag
+			tags[fields[0]] = append(tags[fields[0]], fields[1])
+		case 3: // annotated tag, peeled to its commit
+			tags[fields[1]] = append(tags[fields[1]], fields[2])
+		}
+	}
+	return tags, nil
+}
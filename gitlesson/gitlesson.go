@@ -0,0 +1,327 @@
+// Package gitlesson generates lessons from git history, for tutorials that
+// build a project step by step. Each commit's message is narrated and its
+// changes are replayed in the IDE: new files are created and typed out,
+// modified files are edited line by line, and deleted and renamed files are
+// deleted and renamed in the file explorer.
+package gitlesson
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/codevideo/codevideo-cli/types"
+)
+
+// pathSeparator joins the two paths of rename and set-contents actions.
+const pathSeparator = "_____"
+
+// Options controls how commits are split into lessons and named.
+type Options struct {
+	// Name names the lesson or course; the repository's directory name by default.
+	Name string
+	// CommitsPerLesson starts a new lesson every N commits; 0 for no limit.
+	CommitsPerLesson int
+	// PerTag starts a new lesson after each tagged commit.
+	PerTag bool
+}
+
+// splits reports whether the commits are split into the lessons of a course.
+func (o Options) splits() bool {
+	return o.CommitsPerLesson > 0 || o.PerTag
+}
+
+// Generate walks the commits of revisionRange (e.g. v1.0..v2.0 or
+// main~10..main) in the repository at dir and returns a types.Lesson, or a
+// types.Course with a lesson per tag or per CommitsPerLesson commits when
+// options split the commits.
+func Generate(ctx context.Context, dir string, revisionRange string, options Options) (types.Project, error) {
+	repo := repository{dir: dir}
+	commits, err := repo.commits(ctx, revisionRange)
+	if err != nil {
+		return nil, err
+	}
+	if len(commits) == 0 {
+		return nil, fmt.Errorf("no commits in %s", revisionRange)
+	}
+	if options.Name == "" {
+		absolute, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, err
+		}
+		options.Name = filepath.Base(absolute)
+	}
+
+	var tags map[string][]string
+	if options.PerTag {
+		if tags, err = repo.tags(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	var chunks [][]commit
+	var chunk []commit
+	for _, c := range commits {
+		chunk = append(chunk, c)
+		if (options.PerTag && len(tags[c.hash]) > 0) || (options.CommitsPerLesson > 0 && len(chunk) == options.CommitsPerLesson) {
+			chunks = append(chunks, chunk)
+			chunk = nil
+		}
+	}
+	if len(chunk) > 0 {
+		chunks = append(chunks, chunk)
+	}
+
+	if !options.splits() {
+		lesson, err := generateLesson(ctx, repo, commits)
+		if err != nil {
+			return nil, err
+		}
+		lesson.ID = slug(options.Name)
+		lesson.Name = options.Name
+		lesson.Description = fmt.Sprintf("%s, %s", revisionRange, plural(len(commits), "commit"))
+		return *lesson, nil
+	}
+
+	course := types.Course{
+		ID:          slug(options.Name),
+		Name:        options.Name,
+		Description: fmt.Sprintf("%s, %s in %s", revisionRange, plural(len(commits), "commit"), plural(len(chunks), "lesson")),
+	}
+	for i, chunk := range chunks {
+		lesson, err := generateLesson(ctx, repo, chunk)
+		if err != nil {
+			return nil, err
+		}
+		last := chunk[len(chunk)-1]
+		if names := tags[last.hash]; len(names) > 0 {
+			lesson.Name = names[len(names)-1]
+		} else {
+			lesson.Name = chunk[0].subject
+		}
+		lesson.ID = fmt.Sprintf("%s-%d", course.ID, i+1)
+		lesson.Description = plural(len(chunk), "commit")
+		course.Lessons = append(course.Lessons, *lesson)
+	}
+	return course, nil
+}
+
+// lessonBuilder tracks the IDE while a lesson's commits are replayed.
+type lessonBuilder struct {
+	repo    repository
+	actions []types.Action
+	editors map[string]*editor // open files, and files about to be opened
+	current string             // the file shown in the editor
+	skipped map[string]bool    // binary files
+}
+
+// generateLesson replays commits in a lesson. Files the commits change that
+// already existed are set up in the file explorer first.
+func generateLesson(ctx context.Context, repo repository, commits []commit) (*types.Lesson, error) {
+	b := &lessonBuilder{repo: repo, editors: map[string]*editor{}, skipped: map[string]bool{}}
+
+	changes := make([][]change, len(commits))
+	for i, c := range commits {
+		var err error
+		if changes[i], err = repo.changes(ctx, c); err != nil {
+			return nil, err
+		}
+	}
+	if err := b.setUp(ctx, commits[0].parent, changes); err != nil {
+		return nil, err
+	}
+
+	for i, c := range commits {
+		for _, paragraph := range narration(c) {
+			b.add("author-speak-before", paragraph)
+		}
+		for _, ch := range changes[i] {
+			if err := b.replay(ctx, c, ch); err != nil {
+				return nil, fmt.Errorf("commit %.7s, %s: %w", c.hash, ch.path, err)
+			}
+		}
+	}
+	return &types.Lesson{Actions: b.actions}, nil
+}
+
+// setUp creates the files that existed at base, before the lesson, and that
+// the lesson changes.
+func (b *lessonBuilder) setUp(ctx context.Context, base string, changes [][]change) error {
+	touched := map[string]bool{}
+	for _, commitChanges := range changes {
+		for _, ch := range commitChanges {
+			path := ch.path
+			if ch.status == 'R' {
+				path = ch.oldPath
+			}
+			if touched[path] {
+				continue
+			}
+			touched[path] = true
+			if ch.status == 'A' || base == "" {
+				continue
+			}
+			content, err := b.repo.file(ctx, base, path)
+			if err != nil {
+				return err
+			}
+			if binary(content) {
+				b.skipped[path] = true
+				continue
+			}
+			b.add("file-explorer-create-file", path)
+			if len(content) > 0 {
+				b.add("file-explorer-set-file-contents", path+pathSeparator+string(content))
+			}
+		}
+	}
+	return nil
+}
+
+func (b *lessonBuilder) replay(ctx context.Context, c commit, ch change) error {
+	switch ch.status {
+	case 'A':
+		content, err := b.repo.file(ctx, c.hash, ch.path)
+		if err != nil {
+			return err
+		}
+		if binary(content) {
+			log.Printf("Skipping binary file %s in commit %.7s", ch.path, c.hash)
+			b.skipped[ch.path] = true
+			return nil
+		}
+		delete(b.skipped, ch.path)
+		b.add("file-explorer-create-file", ch.path)
+		b.open(ch.path)
+		b.typeAll(b.editors[ch.path].typeLines(strings.Split(string(content), "\n")))
+
+	case 'M':
+		if b.skipped[ch.path] {
+			return nil
+		}
+		return b.modify(ctx, c, ch.path, ch.path)
+
+	case 'D':
+		b.close(ch.path)
+		if !b.skipped[ch.path] {
+			b.add("file-explorer-delete-file", ch.path)
+		}
+		delete(b.skipped, ch.path)
+
+	case 'R':
+		if b.skipped[ch.oldPath] {
+			delete(b.skipped, ch.oldPath)
+			b.skipped[ch.path] = true
+			return nil
+		}
+		b.close(ch.oldPath)
+		b.add("file-explorer-rename-file", ch.oldPath+pathSeparator+ch.path)
+		return b.modify(ctx, c, ch.oldPath, ch.path)
+	}
+	return nil
+}
+
+// modify edits path, which was oldPath at c's parent, to its content at c.
+func (b *lessonBuilder) modify(ctx context.Context, c commit, oldPath string, path string) error {
+	content, err := b.repo.file(ctx, c.hash, path)
+	if err != nil {
+		return err
+	}
+	if binary(content) {
+		b.close(path)
+		b.skipped[path] = true
+		return nil
+	}
+	if _, open := b.editors[path]; !open {
+		before, err := b.repo.file(ctx, c.parent, oldPath)
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(before, content) {
+			return nil
+		}
+		b.editors[path] = newEditor(string(before))
+	}
+	b.open(path)
+	b.typeAll(b.editors[path].edit(string(content)))
+	return nil
+}
+
+// open shows path in the editor. Files without an editor are opened empty.
+func (b *lessonBuilder) open(path string) {
+	if b.current == path {
+		return
+	}
+	if _, open := b.editors[path]; !open {
+		b.editors[path] = newEditor("")
+	}
+	b.add("file-explorer-open-file", path)
+	b.current = path
+}
+
+// close closes path if it's open.
+func (b *lessonBuilder) close(path string) {
+	if _, open := b.editors[path]; !open {
+		return
+	}
+	b.add("file-explorer-close-file", path)
+	delete(b.editors, path)
+	if b.current == path {
+		b.current = ""
+	}
+}
+
+// typeAll adds editing actions and saves the file.
+func (b *lessonBuilder) typeAll(actions []types.Action) {
+	if len(actions) == 0 {
+		return
+	}
+	b.actions = append(b.actions, actions...)
+	b.add("editor-save", "1")
+}
+
+func (b *lessonBuilder) add(name string, value string) {
+	b.actions = append(b.actions, action(name, value))
+}
+
+var trailerPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z-]*: `)
+
+// narration returns the paragraphs of a commit message, without trailers
+// such as Signed-off-by.
+func narration(c commit) []string {
+	paragraphs := []string{c.subject}
+	for _, paragraph := range strings.Split(strings.ReplaceAll(c.body, "\r\n", "\n"), "\n\n") {
+		lines := strings.Split(strings.TrimSpace(paragraph), "\n")
+		trailers := true
+		for i, line := range lines {
+			lines[i] = strings.TrimSpace(line)
+			trailers = trailers && trailerPattern.MatchString(lines[i])
+		}
+		if text := strings.Join(lines, " "); text != "" && !trailers {
+			paragraphs = append(paragraphs, text)
+		}
+	}
+	return paragraphs
+}
+
+func binary(content []byte) bool {
+	return bytes.IndexByte(content, 0) >= 0
+}
+
+var slugPattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+func slug(name string) string {
+	return strings.Trim(slugPattern.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
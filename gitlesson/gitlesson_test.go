@@ -0,0 +1,251 @@
+package gitlesson
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/codevideo/codevideo-cli/types"
+)
+
+// player replays file explorer and editor actions the way the CodeVideo
+// player does.
+type player struct {
+	files   map[string]string
+	editors map[string]*playerEditor
+	current string
+}
+
+type playerEditor struct {
+	lines    [][]uint16
+	row, col int
+}
+
+func newPlayer() *player {
+	return &player{files: map[string]string{}, editors: map[string]*playerEditor{}}
+}
+
+func (p *player) apply(t *testing.T, action types.Action) {
+	t.Helper()
+	times, _ := strconv.Atoi(action.Value)
+	e := p.editors[p.current]
+	switch action.Name {
+	case "author-speak-before", "editor-highlight-code":
+	case "file-explorer-create-file":
+		p.files[action.Value] = ""
+	case "file-explorer-set-file-contents":
+		path, content, _ := strings.Cut(action.Value, pathSeparator)
+		p.files[path] = content
+	case "file-explorer-delete-file":
+		delete(p.files, action.Value)
+	case "file-explorer-rename-file":
+		from, to, _ := strings.Cut(action.Value, pathSeparator)
+		p.files[to] = p.files[from]
+		delete(p.files, from)
+	case "file-explorer-open-file":
+		if _, ok := p.editors[action.Value]; !ok {
+			e := &playerEditor{}
+			for _, line := range strings.Split(p.files[action.Value], "\n") {
+				e.lines = append(e.lines, utf16.Encode([]rune(line)))
+			}
+			p.editors[action.Value] = e
+		}
+		p.current = action.Value
+	case "file-explorer-close-file":
+		delete(p.editors, action.Value)
+	case "editor-save":
+		var lines []string
+		for _, line := range e.lines {
+			lines = append(lines, string(utf16.Decode(line)))
+		}
+		p.files[p.current] = strings.Join(lines, "\n")
+	case "editor-type":
+		for i, text := range strings.Split(action.Value, "\n") {
+			if i > 0 {
+				p.apply(t, types.Action{Name: "editor-enter", Value: "1"})
+			}
+			units := utf16.Encode([]rune(text))
+			line := e.lines[e.row]
+			e.lines[e.row] = append(append(append([]uint16{}, line[:e.col]...), units...), line[e.col:]...)
+			e.col += len(units)
+		}
+	case "editor-enter":
+		for range times {
+			line := e.lines[e.row]
+			before, after := append([]uint16{}, line[:e.col]...), append([]uint16{}, line[e.col:]...)
+			e.lines[e.row] = before
+			e.lines = append(e.lines[:e.row+1], append([][]uint16{after}, e.lines[e.row+1:]...)...)
+			e.row++
+			e.col = 0
+		}
+	case "editor-backspace":
+		for range times {
+			if e.col > 0 {
+				line := e.lines[e.row]
+				e.lines[e.row] = append(append([]uint16{}, line[:e.col-1]...), line[e.col:]...)
+				e.col--
+			} else if e.row > 0 {
+				e.col = len(e.lines[e.row-1])
+				e.lines[e.row-1] = append(e.lines[e.row-1], e.lines[e.row]...)
+				e.lines = append(e.lines[:e.row], e.lines[e.row+1:]...)
+				e.row--
+			}
+		}
+	case "editor-arrow-down":
+		for range times {
+			if e.row < len(e.lines)-1 {
+				e.row++
+			}
+		}
+	case "editor-arrow-up":
+		for range times {
+			if e.row > 0 {
+				e.row--
+			}
+		}
+	case "editor-command-left":
+		e.col = 0
+	case "editor-command-right":
+		if e.col < len(e.lines[e.row]) {
+			e.col = len(e.lines[e.row])
+		}
+	default:
+		t.Fatalf("unexpected action %+v", action)
+	}
+	if e != nil && (e.row >= len(e.lines) || e.col > len(e.lines[e.row])) {
+		t.Fatalf("caret out of bounds after %+v", action)
+	}
+}
+
+func TestEditReplaysDiffs(t *testing.T) {
+	tests := []struct{ before, after string }{
+		{"", "package main\n\nfunc main() {}\n"},
+		{"a\nb\nc\n", "a\nB\nc\n"},
+		{"a\nb\nc\n", "a\nc\n"},
+		{"a\nb\nc\n", "b\nc\n"},
+		{"a\nb\nc", "a\nb"},
+		{"a\nb\nc", "a\nb\nc\nd\ne"},
+		{"a\nb\nc\n", "x\na\ny\nb\nz\n"},
+		{"a\nb\nc\n", ""},
+		{"one\n  long line\nthree\nfour\n", "one\nthree\nfour is longer 🎉\n  short\n"},
+		{"func a() {\n\treturn 1\n}\n\nfunc b() {\n\treturn 2\n}\n", "func b() {\n\treturn 2\n}\n\nfunc a() {\n\treturn 1\n}\n"},
+	}
+	for _, test := range tests {
+		p := newPlayer()
+		p.files["f"] = test.before
+		p.apply(t, action("file-explorer-open-file", "f"))
+		e := newEditor(test.before)
+		for _, action := range append(e.edit(test.after), action("editor-save", "1")) {
+			p.apply(t, action)
+		}
+		if p.files["f"] != test.after {
+			t.Errorf("editing %q to %q gave %q", test.before, test.after, p.files["f"])
+		}
+		if e.content() != test.after {
+			t.Errorf("editor model of %q to %q is %q", test.before, test.after, e.content())
+		}
+	}
+}
+
+func git(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@example.com", "GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@example.com")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, output)
+	}
+}
+
+func commitFiles(t *testing.T, dir string, message string, files map[string]string) {
+	t.Helper()
+	for path, content := range files {
+		full := filepath.Join(dir, path)
+		if content == "" {
+			git(t, dir, "rm", "-q", path)
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		git(t, dir, "add", path)
+	}
+	git(t, dir, "commit", "-q", "-m", message)
+}
+
+func TestGenerate(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	git(t, dir, "init", "-q")
+	commitFiles(t, dir, "Start the project", map[string]string{"README.md": "# Todo\n"})
+	git(t, dir, "tag", "start")
+	commitFiles(t, dir, "Add the entry point\n\nIt prints a greeting.\n\nSigned-off-by: Test <test@example.com>", map[string]string{
+		"src/main.js": "console.log('hello');\n",
+		"logo.png":    "\x89PNG\x00\x01",
+	})
+	commitFiles(t, dir, "Greet by name", map[string]string{
+		"src/main.js": "const name = 'world';\nconsole.log(`hello ${name}`);\n",
+		"README.md":   "# Todo\n\nRun `node src/main.js`.\n",
+	})
+	git(t, dir, "tag", "-a", "-m", "first part", "part-1")
+	git(t, dir, "mv", "src/main.js", "src/index.js")
+	commitFiles(t, dir, "Rename the entry point", map[string]string{
+		"src/index.js": "const name = 'world';\nconsole.log(`hello ${name}`);\nconsole.log('bye');\n",
+	})
+	commitFiles(t, dir, "Drop the readme", map[string]string{"README.md": ""})
+
+	project, err := Generate(context.Background(), dir, "start..HEAD", Options{Name: "Todo App"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lesson, ok := project.(types.Lesson)
+	if !ok {
+		t.Fatalf("got a %s, want a Lesson", project.GetType())
+	}
+	if lesson.ID != "todo-app" || lesson.Actions[0] != action("file-explorer-create-file", "README.md") {
+		t.Errorf("lesson %q starts with %+v, want the readme set up", lesson.ID, lesson.Actions[0])
+	}
+	p := newPlayer()
+	var spoken []string
+	for _, action := range lesson.Actions {
+		p.apply(t, action)
+		if action.Name == "author-speak-before" {
+			spoken = append(spoken, action.Value)
+		}
+	}
+	want := map[string]string{"src/index.js": "const name = 'world';\nconsole.log(`hello ${name}`);\nconsole.log('bye');\n"}
+	if len(p.files) != len(want) || p.files["src/index.js"] != want["src/index.js"] {
+		t.Errorf("files after the lesson = %q, want %q", p.files, want)
+	}
+	if strings.Join(spoken, "|") != "Add the entry point|It prints a greeting.|Greet by name|Rename the entry point|Drop the readme" {
+		t.Errorf("narration = %q", spoken)
+	}
+
+	project, err = Generate(context.Background(), dir, "start..HEAD", Options{Name: "Todo App", PerTag: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	course, ok := project.(types.Course)
+	if !ok || len(course.Lessons) != 2 {
+		t.Fatalf("got %+v, want a course with 2 lessons", project)
+	}
+	if course.Lessons[0].Name != "part-1" || course.Lessons[1].Name != "Rename the entry point" {
+		t.Errorf("lessons are named %q and %q", course.Lessons[0].Name, course.Lessons[1].Name)
+	}
+	p = newPlayer()
+	for _, action := range course.Lessons[1].Actions {
+		p.apply(t, action)
+	}
+	if p.files["src/index.js"] != want["src/index.js"] {
+		t.Errorf("second lesson ends with src/index.js = %q", p.files["src/index.js"])
+	}
+}
@@ -0,0 +1,119 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/codevideo/codevideo-cli/cloud"
+	"github.com/codevideo/codevideo-cli/constants"
+	"github.com/codevideo/codevideo-cli/elevenlabs"
+	"github.com/codevideo/codevideo-cli/utils"
+)
+
+// ReadinessChecks returns the checks a serve instance must pass before it can
+// render: local tooling, the static server at staticURL, and the TTS and
+// storage backends.
+func ReadinessChecks(staticURL string) []Check {
+	return []Check{
+		{Name: "work-folders", Run: WorkFoldersWritable},
+		{Name: "ffmpeg", Run: FFmpeg},
+		{Name: "node", Run: Node},
+		{Name: "puppeteer-runner", Run: PuppeteerRunner},
+		{Name: "chrome", Run: Chrome},
+		{Name: "static-server", Run: StaticServer(staticURL)},
+		{Name: "tts", Run: TTS},
+		{Name: "storage", Run: Storage},
+	}
+}
+
+// WorkFoldersWritable checks that a file can be created in every work folder.
+func WorkFoldersWritable(ctx context.Context) error {
+	for _, dir := range []string{constants.NewFolder(), constants.ErrorFolder(), constants.SuccessFolder(), constants.VideoFolder()} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("cannot create %s: %w", dir, err)
+		}
+		probe, err := os.CreateTemp(dir, ".healthcheck-*")
+		if err != nil {
+			return fmt.Errorf("%s is not writable: %w", dir, err)
+		}
+		probe.Close()
+		os.Remove(probe.Name())
+	}
+	return nil
+}
+
+// FFmpeg checks that ffmpeg resolves.
+func FFmpeg(ctx context.Context) error {
+	_, err := utils.ResolveFFmpegPath()
+	return err
+}
+
+// Node checks that node is on PATH.
+func Node(ctx context.Context) error {
+	_, err := utils.ResolveNodePath()
+	return err
+}
+
+// PuppeteerRunner checks that the runner script exists.
+func PuppeteerRunner(ctx context.Context) error {
+	runnerPath := constants.PuppeteerRunnerPath()
+	if _, err := os.Stat(runnerPath); err != nil {
+		return fmt.Errorf("runner script %s is missing: %w", runnerPath, err)
+	}
+	return nil
+}
+
+// Chrome checks that the runner can find a Chrome executable.
+func Chrome(ctx context.Context) error {
+	_, err := utils.ResolveChromePath(ctx)
+	return err
+}
+
+// StaticServer returns a check that the static server answers at url.
+func StaticServer(url string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("static server at %s is not answering: %w", url, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 500 {
+			return fmt.Errorf("static server at %s returned %d", url, resp.StatusCode)
+		}
+		return nil
+	}
+}
+
+// TTS checks that the configured speech provider is reachable.
+func TTS(ctx context.Context) error {
+	switch provider := constants.TTSProvider(); provider {
+	case "elevenlabs":
+		return elevenlabs.CheckReachable(ctx, os.Getenv("ELEVEN_LABS_API_KEY"))
+	case "kokoro":
+		// any HTTP answer means the service is up; it has no dedicated health route
+		url := constants.TTSServiceURL()
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("TTS service at %s is not reachable: %w", url, err)
+		}
+		resp.Body.Close()
+		return nil
+	default:
+		return fmt.Errorf("unknown TTS provider %q (expected elevenlabs or kokoro)", provider)
+	}
+}
+
+// Storage checks that the S3 bucket is reachable with the configured credentials.
+func Storage(ctx context.Context) error {
+	return cloud.CheckS3(ctx)
+}
@@ -0,0 +1,68 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRunReportsEveryCheckInOrder(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	report := Run(ctx, []Check{
+		{Name: "fine", Run: func(context.Context) error { return nil }},
+		{Name: "broken", Run: func(context.Context) error { return errors.New("ffmpeg is required") }},
+		{Name: "hung", Run: func(context.Context) error { time.Sleep(time.Second); return nil }},
+	})
+
+	if report.Status != StatusFail || len(report.Checks) != 3 {
+		t.Fatalf("report = %+v", report)
+	}
+	if fine := report.Checks[0]; fine.Name != "fine" || fine.Status != StatusOK || fine.Error != "" {
+		t.Errorf("fine = %+v", fine)
+	}
+	if broken := report.Checks[1]; broken.Status != StatusFail || broken.Error != "ffmpeg is required" {
+		t.Errorf("broken = %+v", broken)
+	}
+	if hung := report.Checks[2]; hung.Status != StatusFail || hung.LatencyMs >= 1000 {
+		t.Errorf("hung check should time out, got %+v", hung)
+	}
+}
+
+func TestWorkFoldersWritable(t *testing.T) {
+	workDir := t.TempDir()
+	t.Setenv("CODEVIDEO_WORK_DIR", workDir)
+	if err := WorkFoldersWritable(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	entries, _ := os.ReadDir(filepath.Join(workDir, "new"))
+	if len(entries) != 0 {
+		t.Errorf("probe files should be removed, found %d", len(entries))
+	}
+
+	if os.Getuid() == 0 {
+		t.Skip("permissions are not enforced for root")
+	}
+	os.Chmod(filepath.Join(workDir, "video"), 0555)
+	defer os.Chmod(filepath.Join(workDir, "video"), 0755)
+	if err := WorkFoldersWritable(context.Background()); err == nil {
+		t.Error("read-only video folder should fail the check")
+	}
+}
+
+func TestStaticServer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	if err := StaticServer(server.URL)(context.Background()); err != nil {
+		t.Errorf("running server: %v", err)
+	}
+	server.Close()
+	if err := StaticServer(server.URL)(context.Background()); err == nil {
+		t.Error("stopped server should fail the check")
+	}
+}
@@ -0,0 +1,207 @@
+package jobs
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/codevideo/codevideo-cli/constants"
+	"github.com/codevideo/codevideo-cli/files"
+	"github.com/codevideo/codevideo-cli/types"
+	"github.com/codevideo/codevideo-cli/utils"
+)
+
+// State is where a job is in the work folder layout used by
+// server.WatchForManifestFiles; it is the name of the folder its manifest is in.
+type State string
+
+const (
+	StateNew     State = "new"     // waiting for or being processed by the server
+	StateError   State = "error"   // failed (the dead-letter queue)
+	StateSuccess State = "success" // processed successfully
+)
+
+// States lists every state in pipeline order.
+var States = []State{StateNew, StateError, StateSuccess}
+
+// ErrNotFound is returned when no manifest matches a job UUID.
+var ErrNotFound = errors.New("job not found")
+
+// ParseState parses a state name.
+func ParseState(name string) (State, error) {
+	for _, state := range States {
+		if string(state) == name {
+			return state, nil
+		}
+	}
+	return "", fmt.Errorf("invalid job state %q (expected new, error or success)", name)
+}
+
+// Folder returns the work folder holding manifests in the state.
+func (s State) Folder() string {
+	switch s {
+	case StateError:
+		return constants.ErrorFolder()
+	case StateSuccess:
+		return constants.SuccessFolder()
+	default:
+		return constants.NewFolder()
+	}
+}
+
+// Job is a manifest file in one of the work folders.
+type Job struct {
+	UUID     string
+	State    State
+	Path     string
+	Modified time.Time
+	// Error is the manifest's error, or why the manifest could not be read.
+	Error string
+	// Manifest is nil when the file is not a valid manifest.
+	Manifest *types.CodeVideoManifest
+}
+
+// Age returns how long ago the manifest was last written.
+func (j Job) Age(now time.Time) time.Duration {
+	return now.Sub(j.Modified)
+}
+
+// Filter selects jobs; zero fields match everything.
+type Filter struct {
+	States []State
+	// OlderThan matches jobs whose manifest was last written at least this long ago.
+	OlderThan time.Duration
+	// ErrorContains matches jobs whose error contains the text, case-insensitively.
+	ErrorContains string
+}
+
+// Match reports whether the job passes the filter.
+func (f Filter) Match(job Job, now time.Time) bool {
+	if len(f.States) > 0 {
+		found := false
+		for _, state := range f.States {
+			if job.State == state {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.OlderThan > 0 && job.Age(now) < f.OlderThan {
+		return false
+	}
+	if f.ErrorContains != "" && !strings.Contains(strings.ToLower(job.Error), strings.ToLower(f.ErrorContains)) {
+		return false
+	}
+	return true
+}
+
+// List returns the jobs matching the filter, oldest first.
+func List(filter Filter) ([]Job, error) {
+	now := time.Now()
+	var jobs []Job
+	for _, state := range States {
+		entries, err := os.ReadDir(state.Folder())
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s folder: %w", state, err)
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+				continue
+			}
+			job, err := load(filepath.Join(state.Folder(), entry.Name()), state)
+			if err != nil {
+				// the file was moved or removed while listing
+				continue
+			}
+			if filter.Match(job, now) {
+				jobs = append(jobs, job)
+			}
+		}
+	}
+	sort.SliceStable(jobs, func(i, j int) bool { return jobs[i].Modified.Before(jobs[j].Modified) })
+	return jobs, nil
+}
+
+func load(path string, state State) (Job, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return Job{}, err
+	}
+	job := Job{
+		UUID:     strings.TrimSuffix(filepath.Base(path), ".json"),
+		State:    state,
+		Path:     path,
+		Modified: info.ModTime(),
+	}
+	manifest, err := files.UnmarshalManifest(path)
+	if err != nil {
+		job.Error = fmt.Sprintf("invalid manifest: %v", err)
+		return job, nil
+	}
+	job.Manifest = manifest
+	job.Error = manifest.Error
+	if manifest.UUID != "" {
+		job.UUID = manifest.UUID
+	}
+	return job, nil
+}
+
+// Find returns the job with the given UUID in any state.
+func Find(uuid string) (Job, error) {
+	all, err := List(Filter{})
+	if err != nil {
+		return Job{}, err
+	}
+	for _, job := range all {
+		if job.UUID == uuid {
+			return job, nil
+		}
+	}
+	return Job{}, fmt.Errorf("%w: %s", ErrNotFound, uuid)
+}
+
+// Requeue clears a failed job's error and moves its manifest back to the new
+// folder, where a running server picks it up again.
+func Requeue(job Job) error {
+	if job.State != StateError {
+		return fmt.Errorf("job %s is in state %s; only failed jobs can be requeued", job.UUID, job.State)
+	}
+	if job.Manifest == nil {
+		return fmt.Errorf("job %s cannot be requeued: %s", job.UUID, job.Error)
+	}
+	destination := filepath.Join(constants.NewFolder(), filepath.Base(job.Path))
+	if _, err := os.Stat(destination); err == nil {
+		return fmt.Errorf("job %s is already queued", job.UUID)
+	}
+	if err := utils.RemoveManifestField(job.Path, "error"); err != nil {
+		return fmt.Errorf("failed to clear error of job %s: %w", job.UUID, err)
+	}
+	if err := files.MoveFile(job.Path, destination); err != nil {
+		return fmt.Errorf("failed to requeue job %s: %w", job.UUID, err)
+	}
+	return nil
+}
+
+// Purge deletes the job's manifest and any video files left over from it.
+func Purge(job Job) error {
+	if err := os.Remove(job.Path); err != nil {
+		return fmt.Errorf("failed to purge job %s: %w", job.UUID, err)
+	}
+	for _, ext := range []string{".webm", ".mp4"} {
+		video := filepath.Join(constants.VideoFolder(), job.UUID+ext)
+		if err := os.Remove(video); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove %s of job %s: %w", filepath.Base(video), job.UUID, err)
+		}
+	}
+	return nil
+}
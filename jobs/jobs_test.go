@@ -0,0 +1,102 @@
+package jobs
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/codevideo/codevideo-cli/constants"
+	"github.com/codevideo/codevideo-cli/files"
+)
+
+func writeManifest(t *testing.T, folder string, uuid string, body string, age time.Duration) string {
+	t.Helper()
+	if err := os.MkdirAll(folder, 0755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(folder, uuid+".json")
+	if err := os.WriteFile(path, []byte(body), 0644); err != nil {
+		t.Fatal(err)
+	}
+	modified := time.Now().Add(-age)
+	if err := os.Chtimes(path, modified, modified); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestListFiltersByStateAgeAndError(t *testing.T) {
+	t.Setenv("CODEVIDEO_WORK_DIR", t.TempDir())
+	writeManifest(t, constants.NewFolder(), "queued", `{"uuid":"queued"}`, time.Minute)
+	writeManifest(t, constants.ErrorFolder(), "chrome", `{"uuid":"chrome","error":"Puppeteer recording failed: Target closed"}`, 3*time.Hour)
+	writeManifest(t, constants.ErrorFolder(), "upload", `{"uuid":"upload","error":"upload stage failed"}`, time.Hour)
+	writeManifest(t, constants.SuccessFolder(), "broken", `{not json`, 48*time.Hour)
+
+	all, err := List(Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 4 || all[0].UUID != "broken" || all[0].Manifest != nil || all[0].Error == "" {
+		t.Fatalf("List() = %+v", all)
+	}
+
+	failed, err := List(Filter{States: []State{StateError}, OlderThan: 2 * time.Hour, ErrorContains: "target CLOSED"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(failed) != 1 || failed[0].UUID != "chrome" {
+		t.Fatalf("filtered List() = %+v", failed)
+	}
+}
+
+func TestRequeueClearsErrorAndMovesToNew(t *testing.T) {
+	t.Setenv("CODEVIDEO_WORK_DIR", t.TempDir())
+	writeManifest(t, constants.ErrorFolder(), "abc", `{"uuid":"abc","error":"boom","attempts":[{"stage":"upload","attempt":1}]}`, time.Hour)
+
+	job, err := Find("abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Requeue(job); err != nil {
+		t.Fatal(err)
+	}
+	manifest, err := files.UnmarshalManifest(filepath.Join(constants.NewFolder(), "abc.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Error != "" || len(manifest.Attempts) != 1 {
+		t.Errorf("requeued manifest = %+v", manifest)
+	}
+
+	queued, _ := Find("abc")
+	if err := Requeue(queued); err == nil {
+		t.Error("requeueing a queued job should fail")
+	}
+	if _, err := Find("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Find(missing) = %v", err)
+	}
+}
+
+func TestPurgeRemovesLeftoverVideos(t *testing.T) {
+	t.Setenv("CODEVIDEO_WORK_DIR", t.TempDir())
+	writeManifest(t, constants.ErrorFolder(), "abc", `{"uuid":"abc","error":"boom"}`, time.Hour)
+	webm := filepath.Join(constants.VideoFolder(), "abc.webm")
+	os.MkdirAll(constants.VideoFolder(), 0755)
+	os.WriteFile(webm, []byte("webm"), 0644)
+
+	job, err := Find("abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Purge(job); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(webm); !os.IsNotExist(err) {
+		t.Error("leftover webm should be removed")
+	}
+	if _, err := Find("abc"); !errors.Is(err, ErrNotFound) {
+		t.Error("purged job should be gone")
+	}
+}
@@ -0,0 +1,179 @@
+package ledger
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Entry reasons.
+const (
+	// ReasonRender charges the tokens of a delivered job.
+	ReasonRender = "render"
+)
+
+// Entry is one line of the ledger. Delta is negative for charges.
+type Entry struct {
+	Time        time.Time `json:"time"`
+	UserID      string    `json:"userId"`
+	Environment string    `json:"environment"`
+	JobUUID     string    `json:"jobUuid"`
+	Delta       int       `json:"delta"`
+	Reason      string    `json:"reason"`
+}
+
+// Ledger is an append-only JSON-lines file of token changes. It is the
+// source of truth for what users were charged; the user directory's balances
+// are reconciled against it.
+type Ledger struct {
+	path string
+	mu   sync.Mutex
+}
+
+// Open returns the ledger at path; the file is created on the first Record.
+func Open(path string) *Ledger {
+	return &Ledger{path: path}
+}
+
+// Path returns the ledger file.
+func (l *Ledger) Path() string {
+	return l.path
+}
+
+// Record appends the entry unless the ledger already holds one for the same
+// job and reason, and reports whether it was appended.
+func (l *Ledger) Record(entry Entry) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entries, err := l.read()
+	if err != nil {
+		return false, err
+	}
+	for _, existing := range entries {
+		if existing.JobUUID == entry.JobUUID && existing.Reason == entry.Reason {
+			return false, nil
+		}
+	}
+
+	if entry.Time.IsZero() {
+		entry.Time = time.Now().UTC()
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return false, err
+	}
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return false, fmt.Errorf("failed to create ledger folder: %w", err)
+	}
+	file, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return false, fmt.Errorf("failed to open ledger: %w", err)
+	}
+	defer file.Close()
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return false, fmt.Errorf("failed to append to ledger: %w", err)
+	}
+	// the ledger is what reconciliation trusts, so make the entry durable
+	return true, file.Sync()
+}
+
+// Entries returns every entry in the order recorded.
+func (l *Ledger) Entries() ([]Entry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.read()
+}
+
+func (l *Ledger) read() ([]Entry, error) {
+	file, err := os.Open(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ledger: %w", err)
+	}
+	defer file.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("ledger %s line %d: %w", l.path, lineNumber, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// Account identifies a user in one environment.
+type Account struct {
+	Environment string
+	UserID      string
+}
+
+// Charged sums the tokens charged to each account.
+func Charged(entries []Entry) map[Account]int {
+	charged := make(map[Account]int)
+	for _, entry := range entries {
+		charged[Account{entry.Environment, entry.UserID}] -= entry.Delta
+	}
+	return charged
+}
+
+// Usage is what one account used in one month.
+type Usage struct {
+	Month       string `json:"month"` // YYYY-MM, UTC
+	Environment string `json:"environment"`
+	UserID      string `json:"userId"`
+	Jobs        int    `json:"jobs"`
+	Tokens      int    `json:"tokens"`
+}
+
+// MonthlyUsage totals the rendered jobs and charged tokens per account and
+// month, ordered by month, environment and user.
+func MonthlyUsage(entries []Entry) []Usage {
+	type key struct {
+		month string
+		Account
+	}
+	totals := make(map[key]*Usage)
+	for _, entry := range entries {
+		k := key{entry.Time.UTC().Format("2006-01"), Account{entry.Environment, entry.UserID}}
+		usage, ok := totals[k]
+		if !ok {
+			usage = &Usage{Month: k.month, Environment: entry.Environment, UserID: entry.UserID}
+			totals[k] = usage
+		}
+		if entry.Reason == ReasonRender {
+			usage.Jobs++
+		}
+		usage.Tokens -= entry.Delta
+	}
+
+	report := make([]Usage, 0, len(totals))
+	for _, usage := range totals {
+		report = append(report, *usage)
+	}
+	sort.Slice(report, func(i, j int) bool {
+		a, b := report[i], report[j]
+		if a.Month != b.Month {
+			return a.Month < b.Month
+		}
+		if a.Environment != b.Environment {
+			return a.Environment < b.Environment
+		}
+		return a.UserID < b.UserID
+	})
+	return report
+}
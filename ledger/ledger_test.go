@@ -0,0 +1,58 @@
+package ledger
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRecordIsIdempotentPerJob(t *testing.T) {
+	l := Open(filepath.Join(t.TempDir(), "ledger.jsonl"))
+	entry := Entry{UserID: "ada", Environment: "production", JobUUID: "job-1", Delta: -20, Reason: ReasonRender}
+
+	appended, err := l.Record(entry)
+	if err != nil || !appended {
+		t.Fatalf("first Record = %v, %v", appended, err)
+	}
+	appended, err = l.Record(entry)
+	if err != nil || appended {
+		t.Fatalf("second Record of the same job = %v, %v; want not appended", appended, err)
+	}
+
+	entries, err := l.Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Time.IsZero() {
+		t.Fatalf("entries = %+v", entries)
+	}
+	if charged := Charged(entries)[Account{"production", "ada"}]; charged != 20 {
+		t.Errorf("charged = %d, want 20", charged)
+	}
+}
+
+func TestMonthlyUsage(t *testing.T) {
+	september := time.Date(2026, 9, 30, 23, 0, 0, 0, time.UTC)
+	october := time.Date(2026, 10, 2, 8, 0, 0, 0, time.UTC)
+	entries := []Entry{
+		{Time: october, UserID: "bob", Environment: "production", JobUUID: "3", Delta: -10, Reason: ReasonRender},
+		{Time: september, UserID: "ada", Environment: "production", JobUUID: "1", Delta: -20, Reason: ReasonRender},
+		{Time: october, UserID: "ada", Environment: "production", JobUUID: "2", Delta: -10, Reason: ReasonRender},
+		{Time: october, UserID: "ada", Environment: "production", JobUUID: "4", Delta: -30, Reason: ReasonRender},
+	}
+
+	report := MonthlyUsage(entries)
+	want := []Usage{
+		{Month: "2026-09", Environment: "production", UserID: "ada", Jobs: 1, Tokens: 20},
+		{Month: "2026-10", Environment: "production", UserID: "ada", Jobs: 2, Tokens: 40},
+		{Month: "2026-10", Environment: "production", UserID: "bob", Jobs: 1, Tokens: 10},
+	}
+	if len(report) != len(want) {
+		t.Fatalf("report = %+v", report)
+	}
+	for i := range want {
+		if report[i] != want[i] {
+			t.Errorf("report[%d] = %+v, want %+v", i, report[i], want[i])
+		}
+	}
+}
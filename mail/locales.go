@@ -0,0 +1,112 @@
+package mail
+
+import "strings"
+
+// DefaultLocale is used when a job doesn't specify a locale or asks for one we
+// have no translations for.
+const DefaultLocale = "en"
+
+// messages holds the translated strings available to email templates via the
+// "t" template function. The locales match the ones in data/multilingual.
+var messages = map[string]map[string]string{
+	"en": {
+		"success.subject":  "CodeVideo Generated!",
+		"success.heading":  "CodeVideo Generated!",
+		"success.intro":    "Your video has been generated and is available for download:",
+		"success.download": "Download Video",
+		"success.fallback": "If the link doesn't trigger a download, copy and paste this into your browser:",
+		"failure.subject":  "CodeVideo generation failed",
+		"failure.heading":  "We couldn't generate your CodeVideo",
+		"failure.intro":    "Something went wrong while rendering your video. No action is needed to keep your project; you can try again at any time.",
+		"failure.error":    "Error summary:",
+		"failure.support":  "If this keeps happening, reply to this email and we'll take a look.",
+		"label.course":     "Course",
+		"label.lesson":     "Lesson",
+		"label.duration":   "Duration",
+	},
+	"de": {
+		"success.subject":  "CodeVideo erstellt!",
+		"success.heading":  "CodeVideo erstellt!",
+		"success.intro":    "Dein Video wurde erstellt und steht zum Download bereit:",
+		"success.download": "Video herunterladen",
+		"success.fallback": "Falls der Link keinen Download startet, kopiere diese Adresse in deinen Browser:",
+		"failure.subject":  "CodeVideo-Erstellung fehlgeschlagen",
+		"failure.heading":  "Wir konnten dein CodeVideo nicht erstellen",
+		"failure.intro":    "Beim Rendern deines Videos ist etwas schiefgelaufen. Dein Projekt bleibt erhalten und du kannst es jederzeit erneut versuchen.",
+		"failure.error":    "Fehlerzusammenfassung:",
+		"failure.support":  "Wenn das wiederholt passiert, antworte einfach auf diese E-Mail und wir sehen es uns an.",
+		"label.course":     "Kurs",
+		"label.lesson":     "Lektion",
+		"label.duration":   "Dauer",
+	},
+	"es": {
+		"success.subject":  "¡CodeVideo generado!",
+		"success.heading":  "¡CodeVideo generado!",
+		"success.intro":    "Tu video se ha generado y está disponible para descargar:",
+		"success.download": "Descargar video",
+		"success.fallback": "Si el enlace no inicia la descarga, copia y pega esta dirección en tu navegador:",
+		"failure.subject":  "Error al generar CodeVideo",
+		"failure.heading":  "No pudimos generar tu CodeVideo",
+		"failure.intro":    "Algo salió mal al renderizar tu video. Tu proyecto se conserva y puedes intentarlo de nuevo en cualquier momento.",
+		"failure.error":    "Resumen del error:",
+		"failure.support":  "Si esto sigue ocurriendo, responde a este correo y lo revisaremos.",
+		"label.course":     "Curso",
+		"label.lesson":     "Lección",
+		"label.duration":   "Duración",
+	},
+	"pt": {
+		"success.subject":  "CodeVideo gerado!",
+		"success.heading":  "CodeVideo gerado!",
+		"success.intro":    "Seu vídeo foi gerado e está disponível para download:",
+		"success.download": "Baixar vídeo",
+		"success.fallback": "Se o link não iniciar o download, copie e cole este endereço no seu navegador:",
+		"failure.subject":  "Falha ao gerar o CodeVideo",
+		"failure.heading":  "Não foi possível gerar seu CodeVideo",
+		"failure.intro":    "Algo deu errado ao renderizar seu vídeo. Seu projeto foi mantido e você pode tentar novamente a qualquer momento.",
+		"failure.error":    "Resumo do erro:",
+		"failure.support":  "Se isso continuar acontecendo, responda a este e-mail e vamos verificar.",
+		"label.course":     "Curso",
+		"label.lesson":     "Lição",
+		"label.duration":   "Duração",
+	},
+	"zh": {
+		"success.subject":  "CodeVideo 已生成！",
+		"success.heading":  "CodeVideo 已生成！",
+		"success.intro":    "你的视频已生成，可以下载：",
+		"success.download": "下载视频",
+		"success.fallback": "如果链接没有开始下载，请将此地址复制到浏览器中：",
+		"failure.subject":  "CodeVideo 生成失败",
+		"failure.heading":  "我们无法生成你的 CodeVideo",
+		"failure.intro":    "渲染视频时出现了问题。你的项目已保留，可以随时重试。",
+		"failure.error":    "错误摘要：",
+		"failure.support":  "如果问题持续出现，请直接回复此邮件，我们会进行排查。",
+		"label.course":     "课程",
+		"label.lesson":     "课时",
+		"label.duration":   "时长",
+	},
+}
+
+// NormalizeLocale maps a locale such as "pt-BR" or "de_DE" onto one of the
+// supported message tables, falling back to DefaultLocale.
+func NormalizeLocale(locale string) string {
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	if i := strings.IndexAny(locale, "-_"); i >= 0 {
+		locale = locale[:i]
+	}
+	if _, ok := messages[locale]; ok {
+		return locale
+	}
+	return DefaultLocale
+}
+
+// translate returns the message for key in locale, falling back to English and
+// finally to the key itself so a missing translation is visible but harmless.
+func translate(locale, key string) string {
+	if msg, ok := messages[locale][key]; ok {
+		return msg
+	}
+	if msg, ok := messages[DefaultLocale][key]; ok {
+		return msg
+	}
+	return key
+}
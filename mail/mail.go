@@ -8,8 +8,8 @@ import (
 	"github.com/mailjet/mailjet-apiv3-go"
 )
 
-// SendEmail sends a notification using Mailjet.
-func SendEmail(userEmail string, mp4Url string) error {
+// SendEmail renders the notification's template and sends it using Mailjet.
+func SendEmail(userEmail string, notification Notification) error {
 	// Get Mailjet API keys from environment variables.
 	mjPublic := os.Getenv("MJ_APIKEY_PUBLIC")
 	mjPrivate := os.Getenv("MJ_APIKEY_PRIVATE")
@@ -17,12 +17,13 @@ func SendEmail(userEmail string, mp4Url string) error {
 		return errors.New("mailjet api keys not set")
 	}
 
-	mailjetClient := mailjet.NewMailjetClient(mjPublic, mjPrivate)
-
-	// Build HTML content for the email.
-	htmlContent := fmt.Sprintf("<h1>CodeVideo Generated!</h1><p>Your video has been generated and is available for download: </p> <a href=\"%s\" download target=\"_blank\">Download Video</a><br/><br/>If the link doesn't trigger a download, copy and paste this into your browser: %s", mp4Url, mp4Url)
+	// Build the subject and HTML content for the email.
+	subject, htmlContent, err := Render(notification)
+	if err != nil {
+		return err
+	}
 
-	// TODO: use the clerk userID to get the email address of the user
+	mailjetClient := mailjet.NewMailjetClient(mjPublic, mjPrivate)
 
 	// Create the email message.
 	message := mailjet.InfoMessagesV31{
@@ -35,7 +36,7 @@ func SendEmail(userEmail string, mp4Url string) error {
 				Email: userEmail,
 			},
 		},
-		Subject:  "CodeVideo Generated!",
+		Subject:  subject,
 		HTMLPart: htmlContent,
 	}
 	messages := mailjet.MessagesV31{
@@ -43,7 +44,7 @@ func SendEmail(userEmail string, mp4Url string) error {
 	}
 
 	// Send the email.
-	_, err := mailjetClient.SendMailV31(&messages)
+	_, err = mailjetClient.SendMailV31(&messages)
 	if err != nil {
 		return fmt.Errorf("mailjet error: %v", err)
 	}
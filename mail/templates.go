@@ -0,0 +1,105 @@
+package mail
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Template names. Each template file defines a "subject" and a "body" block.
+const (
+	TemplateSuccess = "success"
+	TemplateFailure = "failure"
+)
+
+//go:embed templates
+var embeddedTemplates embed.FS
+
+// Notification is the data available to an email template.
+type Notification struct {
+	Template     string        // TemplateSuccess or TemplateFailure
+	Locale       string        // e.g. "en", "de", "pt-BR"
+	LessonName   string        // name of the rendered lesson, if any
+	CourseName   string        // name of the course the lesson belongs to, if any
+	Duration     time.Duration // length of the generated video
+	ThumbnailURL string        // optional preview image
+	VideoURL     string        // download link for the generated video
+	ErrorSummary string        // failure reason for the failure template
+}
+
+// TemplateDir returns the directory that overrides the embedded templates, set
+// via CODEVIDEO_MAIL_TEMPLATE_DIR. A template missing from that directory falls
+// back to the embedded default.
+func TemplateDir() string {
+	return os.Getenv("CODEVIDEO_MAIL_TEMPLATE_DIR")
+}
+
+// Render executes the notification's template and returns the subject and HTML body.
+func Render(n Notification) (string, string, error) {
+	if n.Template == "" {
+		n.Template = TemplateSuccess
+	}
+	if n.Template != TemplateSuccess && n.Template != TemplateFailure {
+		return "", "", fmt.Errorf("unknown email template %q (expected %q or %q)", n.Template, TemplateSuccess, TemplateFailure)
+	}
+	n.Locale = NormalizeLocale(n.Locale)
+
+	source, err := loadTemplate(n.Template)
+	if err != nil {
+		return "", "", err
+	}
+
+	tmpl, err := template.New(n.Template).Funcs(template.FuncMap{
+		"t":        func(key string) string { return translate(n.Locale, key) },
+		"duration": formatDuration,
+	}).Parse(source)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to parse email template %s: %w", n.Template, err)
+	}
+
+	var subject, body bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&subject, "subject", n); err != nil {
+		return "", "", fmt.Errorf("failed to render subject of email template %s: %w", n.Template, err)
+	}
+	if err := tmpl.ExecuteTemplate(&body, "body", n); err != nil {
+		return "", "", fmt.Errorf("failed to render body of email template %s: %w", n.Template, err)
+	}
+	// The subject is plain text, so undo the HTML escaping applied by html/template.
+	return html.UnescapeString(strings.TrimSpace(subject.String())), body.String(), nil
+}
+
+// loadTemplate reads <name>.html from the override directory if present,
+// otherwise from the embedded defaults.
+func loadTemplate(name string) (string, error) {
+	filename := name + ".html"
+	if dir := TemplateDir(); dir != "" {
+		data, err := os.ReadFile(filepath.Join(dir, filename))
+		if err == nil {
+			return string(data), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", fmt.Errorf("failed to read email template override: %w", err)
+		}
+	}
+	data, err := embeddedTemplates.ReadFile("templates/" + filename)
+	if err != nil {
+		return "", fmt.Errorf("failed to read embedded email template %s: %w", filename, err)
+	}
+	return string(data), nil
+}
+
+// formatDuration prints a video length as m:ss (or h:mm:ss for long videos).
+func formatDuration(d time.Duration) string {
+	total := int(d.Round(time.Second).Seconds())
+	hours, minutes, seconds := total/3600, (total%3600)/60, total%60
+	if hours > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", hours, minutes, seconds)
+	}
+	return fmt.Sprintf("%d:%02d", minutes, seconds)
+}
@@ -0,0 +1,72 @@
+package mail
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderSuccessLocalized(t *testing.T) {
+	subject, body, err := Render(Notification{
+		Template:   TemplateSuccess,
+		Locale:     "de-DE",
+		LessonName: "Schleifen & Listen",
+		Duration:   272 * time.Second,
+		VideoURL:   "https://example.com/video.mp4",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subject != "CodeVideo erstellt!: Schleifen & Listen" {
+		t.Errorf("subject = %q", subject)
+	}
+	for _, want := range []string{"Video herunterladen", "4:32", "https://example.com/video.mp4", "Schleifen &amp; Listen"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body is missing %q", want)
+		}
+	}
+}
+
+func TestRenderFailureFallsBackToEnglish(t *testing.T) {
+	_, body, err := Render(Notification{
+		Template:     TemplateFailure,
+		Locale:       "fr",
+		ErrorSummary: "Puppeteer recording failed",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(body, "We couldn&#39;t generate your CodeVideo") || !strings.Contains(body, "Puppeteer recording failed") {
+		t.Errorf("unexpected failure body: %s", body)
+	}
+}
+
+func TestRenderUsesTemplateOverride(t *testing.T) {
+	dir := t.TempDir()
+	override := `{{define "subject"}}Custom {{t "label.lesson"}}{{end}}{{define "body"}}<p>{{.LessonName}}</p>{{end}}`
+	if err := os.WriteFile(filepath.Join(dir, "success.html"), []byte(override), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CODEVIDEO_MAIL_TEMPLATE_DIR", dir)
+
+	subject, body, err := Render(Notification{Template: TemplateSuccess, Locale: "es", LessonName: "Uno"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subject != "Custom Lección" || body != "<p>Uno</p>" {
+		t.Errorf("override not used: subject=%q body=%q", subject, body)
+	}
+
+	// failure.html is not overridden, so the embedded default is used
+	if _, body, err := Render(Notification{Template: TemplateFailure}); err != nil || !strings.Contains(body, "<!DOCTYPE html>") {
+		t.Errorf("expected embedded failure template, got %q (%v)", body, err)
+	}
+}
+
+func TestRenderRejectsUnknownTemplate(t *testing.T) {
+	if _, _, err := Render(Notification{Template: "welcome"}); err == nil {
+		t.Fatal("expected unknown template error")
+	}
+}
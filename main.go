@@ -5,15 +5,26 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"syscall"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"gopkg.in/natefinch/lumberjack.v2"
 
 	"github.com/codevideo/codevideo-cli/cli"
+	"github.com/codevideo/codevideo-cli/cli/commands"
+	"github.com/codevideo/codevideo-cli/cli/config"
+	"github.com/codevideo/codevideo-cli/cli/renderer"
 	"github.com/codevideo/codevideo-cli/cli/staticserver"
 	"github.com/codevideo/codevideo-cli/constants"
+	"github.com/codevideo/codevideo-cli/health"
+	"github.com/codevideo/codevideo-cli/metrics"
+	"github.com/codevideo/codevideo-cli/progress"
 	"github.com/codevideo/codevideo-cli/server"
+	"github.com/codevideo/codevideo-cli/settings"
+	"github.com/codevideo/codevideo-cli/types"
 	"github.com/joho/godotenv"
 	"github.com/spf13/cobra"
 )
@@ -34,11 +45,43 @@ var rootCmd = &cobra.Command{
 			return
 		}
 
+		mode, _ := cmd.Flags().GetString("mode")
+
+		// Resolve how CLI progress is reported; JSON progress on stdout moves
+		// logs and status messages to stderr so stdout stays machine-readable
+		var progressOutput *renderer.ProgressOutput
+		console := io.Writer(os.Stdout)
+		if mode != "serve" {
+			progressFormat, _ := cmd.Flags().GetString("progress")
+			progressFd, _ := cmd.Flags().GetInt("progress-fd")
+			var err error
+			progressOutput, err = renderer.ResolveProgressOutput(progressFormat, progressFd)
+			if err != nil {
+				log.Fatalf("Invalid progress options: %v", err)
+			}
+			if progressOutput.OwnsStdout() {
+				console = os.Stderr
+				renderer.Messages = os.Stderr
+			}
+		}
+
 		// Setup logging configuration
-		setupLogging(cmd)
+		setupLogging(cmd, console)
+
+		// progress events fan out to independent subscribers; Slack only hears
+		// about milestones and is off by default outside of serve mode
+		progress.Subscribe(progress.LogSubscriber)
+		slackProgress, _ := cmd.Flags().GetBool("slack-progress")
+		if !cmd.Flags().Changed("slack-progress") {
+			slackProgress = constants.SlackProgressEnabled(mode == "serve")
+		}
+		if slackProgress {
+			progress.Subscribe(progress.NewSlackSubscriber(constants.SLACK_PROGRESS_MILESTONE))
+		}
 
 		// for either CLI or server mode, we need to start the required servers:
-		// start static server for the built gatsby files (7001) and the manifest files it needs (7000)
+		// the static server for the built gatsby files and the manifest server
+		// for the manifests it needs, each on a free (or configured) loopback port
 		ctx := context.Background()
 		srv, err := staticserver.Start(ctx)
 		if err != nil {
@@ -46,25 +89,58 @@ var rootCmd = &cobra.Command{
 		}
 		defer srv.Stop()
 		if srv.ManifestServerStarted() {
-			log.Printf("Manifest server started on port %d", constants.DEFAULT_MANIFEST_SERVER_PORT)
+			log.Printf("Manifest server started on port %d", srv.ManifestPort)
 		}
-		log.Printf("Static server started on port %d", constants.DEFAULT_GATSBY_PORT)
+		log.Printf("Static server started on port %d", srv.Port)
 
-		mode, _ := cmd.Flags().GetString("mode")
 		if mode == "serve" {
 			// Server functionality (API use case)
-			server.WatchForManifestFiles()
+			tracker := progress.NewTracker(time.Hour)
+			progress.Subscribe(tracker.Subscriber())
+			if apiAddr := constants.APIAddr(); apiAddr != "" {
+				api := server.NewAPI(tracker, health.ReadinessChecks(srv.GetURL()))
+				if err := api.Start(apiAddr); err != nil {
+					// jobs are still processed without the status API
+					log.Errorf("Job status API not started: %v", err)
+				} else {
+					defer api.Stop()
+				}
+			}
+			metricsAddr, _ := cmd.Flags().GetString("metrics-addr")
+			if !cmd.Flags().Changed("metrics-addr") {
+				metricsAddr = constants.MetricsAddr()
+			}
+			if metricsAddr != "" {
+				if err := metrics.Start(metricsAddr); err != nil {
+					log.Fatalf("Error starting metrics server: %v", err)
+				}
+			}
+			// SIGTERM/SIGINT stop new manifests and drain in-flight jobs; the
+			// deferred static server and API stops only run once the drain is done.
+			// A second signal exits immediately.
+			watchCtx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+			go func() {
+				<-watchCtx.Done()
+				stop()
+			}()
+			// the render flags are the defaults for manifests without render settings
+			cfg, err := config.LoadFromFlags(cmd)
+			if err != nil {
+				log.Fatalf("Invalid configuration: %v", err)
+			}
+			server.WatchForManifestFiles(watchCtx, cfg.Render(), srv.Endpoints())
 		} else {
 			// CLI functionality
-			if err := cli.Execute(cmd); err != nil {
+			progress.Subscribe(progressOutput.Subscriber())
+			if err := cli.Execute(cmd, srv.Endpoints()); err != nil {
 				log.Fatalf("CLI execution failed: %v", err)
 			}
 		}
 	},
 }
 
-// Setup logging with rotatable file output by default
-func setupLogging(cmd *cobra.Command) {
+// Setup logging with rotatable file output by default, mirrored to console
+func setupLogging(cmd *cobra.Command, console io.Writer) {
 	verbose, _ := cmd.Flags().GetBool("verbose")
 
 	// Set log level
@@ -89,8 +165,8 @@ func setupLogging(cmd *cobra.Command) {
 		Compress:   true,
 	}
 
-	// Write to both file and stdout
-	multiWriter := io.MultiWriter(os.Stdout, logRotate)
+	// Write to both file and the console
+	multiWriter := io.MultiWriter(console, logRotate)
 	log.SetOutput(multiWriter)
 
 	if verbose {
@@ -105,16 +181,25 @@ func init() {
 	rootCmd.Flags().StringP("mode", "m", "", "Run mode (use 'serve' for file watcher mode)")
 
 	// --project or -p flag for specifying project JSON data
-	rootCmd.Flags().StringP("project", "p", "", "Project data (Actions, Lesson, or Course) in JSON format")
+	rootCmd.Flags().StringP("project", "p", "", "Project data (Actions, Lesson, or Course) in JSON, YAML or Markdown format")
+
+	// --project-type flag for declaring the project type instead of detecting it
+	rootCmd.Flags().String("project-type", "auto", "Project type: auto, course, lesson or actions (auto uses the project's \"type\" or \"$schema\", then detection)")
 
 	// --output or -o flag for specifying output file path
 	rootCmd.Flags().StringP("output", "o", "", "Output file path")
 
 	// --orientation or -n flag for specifying video orientation
-	rootCmd.Flags().StringP("orientation", "n", "landscape", "Video orientation (landscape or portrait)")
+	rootCmd.Flags().StringP("orientation", "n", "landscape", "Video orientation (landscape or portrait; default: render.orientation in codevideo.yaml)")
 
 	// --resolution or -r flag for specifying video resolution
-	rootCmd.Flags().StringP("resolution", "r", "1080p", "Video resolution (1080p or 4K)")
+	rootCmd.Flags().StringP("resolution", "r", "1080p", "Video resolution: 720p, 1080p, 1440p, 4K, 1:1, 4:5 or WIDTHxHEIGHT (default: render.resolution in codevideo.yaml)")
+
+	// --frame-rate flag for the recording and mp4 frame rate
+	rootCmd.Flags().Int("frame-rate", types.DefaultFrameRate, "Video frame rate in frames per second (default: render.frame_rate in codevideo.yaml)")
+
+	// --encoding-profile flag for choosing the ffmpeg quality/speed trade-off
+	rootCmd.Flags().String("encoding-profile", "standard", "Encoding profile (standard, high or draft; default: render.encoding_profile in codevideo.yaml)")
 
 	// --verbose or -v flag for verbose output
 	rootCmd.Flags().BoolP("verbose", "v", false, "Verbose output")
@@ -130,29 +215,52 @@ func init() {
 
 	// --debug or -d flag for enabling debug mode (non-headless browser)
 	rootCmd.Flags().BoolP("debug", "d", false, "Enable debug mode (run browser in non-headless mode)")
+
+	// --progress flag for choosing the CLI progress format
+	rootCmd.Flags().String("progress", renderer.ProgressAuto, "Progress output format: auto, tty, plain or json (auto picks tty for terminals, plain otherwise)")
+
+	// --progress-fd flag for writing progress to another file descriptor
+	rootCmd.Flags().Int("progress-fd", 1, "File descriptor to write progress to (1 = stdout, 2 = stderr, 3+ = inherited descriptor)")
+
+	// --slack-progress flag for posting progress milestones to Slack (default on in serve mode)
+	rootCmd.Flags().Bool("slack-progress", false, "Post progress milestones to Slack (default: on in serve mode, off in CLI mode)")
+
+	// --metrics-addr flag for exposing Prometheus metrics in serve mode
+	rootCmd.Flags().String("metrics-addr", "", "Serve Prometheus metrics on this address in serve mode, e.g. 127.0.0.1:9090 (default: CODEVIDEO_METRICS_ADDR, off when empty)")
+
+	// subcommands
+	rootCmd.AddCommand(commands.NewMailCmd())
+	rootCmd.AddCommand(commands.NewJobsCmd())
+	rootCmd.AddCommand(commands.NewDoctorCmd())
+	rootCmd.AddCommand(commands.NewLedgerCmd())
+	rootCmd.AddCommand(commands.NewConfigCmd())
+	rootCmd.AddCommand(commands.NewSchemaCmd())
+	rootCmd.AddCommand(commands.NewConvertCmd())
+	rootCmd.AddCommand(commands.NewFromGitCmd())
+	rootCmd.AddCommand(commands.NewImportTerminalCmd())
 }
 
 func main() {
-	// Get the executable path
-	execPath, err := os.Executable()
-	if err != nil {
-		log.Fatalf("Error getting executable path: %v", err)
+	// Load CODEVIDEO_ENV_FILE, or .env (.env.example for source installs) next
+	// to the executable; npm installs usually have neither, so that's not a warning.
+	if envFile := constants.EnvFile(); envFile != "" {
+		if err := godotenv.Load(envFile); err != nil {
+			log.Printf("Warning: failed to load env file %s: %v", envFile, err)
+		}
 	}
 
-	// Get the directory of the executable
-	execDir := filepath.Dir(execPath)
-
-	if configured := os.Getenv("CODEVIDEO_ENV_FILE"); configured != "" {
-		if err := godotenv.Load(configured); err != nil {
-			log.Printf("Warning: failed to load CODEVIDEO_ENV_FILE %s: %v", configured, err)
-		}
-	} else if err := godotenv.Load(filepath.Join(execDir, ".env")); err != nil {
-		// Preserve the source-install fallback without warning for npm installs.
-		_ = godotenv.Load(filepath.Join(execDir, ".env.example"))
+	// codevideo.yaml/.toml (project, then user) fill in whatever the
+	// environment doesn't set; flags are applied on top by each command
+	fileSettings, err := settings.Load(nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
 	}
+	fileSettings.Apply()
 
 	// Execute the root command
+	// cobra has already printed the error
 	if err := rootCmd.Execute(); err != nil {
-		log.Fatal(err)
+		os.Exit(1)
 	}
 }
@@ -0,0 +1,195 @@
+package metrics
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/shirou/gopsutil/v3/process"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/codevideo/codevideo-cli/constants"
+)
+
+const namespace = "codevideo"
+
+// Registry holds the CodeVideo collectors. It is separate from the default
+// registry so only serve-mode metrics (plus Go and process metrics) are exposed.
+// Speech is synthesized by the CLI before a manifest is queued, so serve mode
+// has no audio stage or TTS requests to report.
+var Registry = prometheus.NewRegistry()
+
+// inFlight counts the jobs being processed; their manifests are still in the new folder.
+var inFlight atomic.Int64
+
+var (
+	jobsInFlight = prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "jobs_in_flight",
+		Help:      "Jobs currently being processed.",
+	}, func() float64 { return float64(inFlight.Load()) })
+	maxConcurrentJobs = prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "jobs_max_concurrent",
+		Help:      "Maximum number of jobs processed at the same time.",
+	}, func() float64 { return float64(constants.MaxConcurrentJobs()) })
+	queueDepth = prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "queue_depth",
+		Help:      "Manifests in the new folder that are not being processed yet.",
+	}, func() float64 { return float64(max(int64(countManifests(constants.NewFolder()))-inFlight.Load(), 0)) })
+	stageDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "stage_duration_seconds",
+		Help:      "Time spent in each pipeline stage, including retries.",
+		// upload takes seconds, recording takes minutes
+		Buckets: []float64{1, 5, 15, 30, 60, 120, 300, 600, 1200, 2400},
+	}, []string{"stage"})
+	stageFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "stage_failures_total",
+		Help:      "Jobs that failed in each pipeline stage after exhausting their retries.",
+	}, []string{"stage"})
+	uploadedBytes = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "uploaded_bytes_total",
+		Help:      "Bytes of rendered videos uploaded to storage.",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		jobsInFlight,
+		maxConcurrentJobs,
+		queueDepth,
+		stageDuration,
+		stageFailures,
+		uploadedBytes,
+		newChildRSSCollector("chrome", "ffmpeg"),
+	)
+}
+
+// JobStarted marks a job as in flight; call the returned function when it is done.
+func JobStarted() (done func()) {
+	inFlight.Add(1)
+	return func() { inFlight.Add(-1) }
+}
+
+// ObserveStage records how long a stage took and, if it failed, counts the failure.
+func ObserveStage(stage string, duration time.Duration, err error) {
+	stageDuration.WithLabelValues(stage).Observe(duration.Seconds())
+	if err != nil {
+		stageFailures.WithLabelValues(stage).Inc()
+	}
+}
+
+// AddUploadedBytes counts bytes uploaded to storage.
+func AddUploadedBytes(n int) {
+	uploadedBytes.Add(float64(n))
+}
+
+// Handler serves the registry in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// Start serves /metrics on addr in the background.
+func Start(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("metrics address %s is unavailable: %w", addr, err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", Handler())
+	go func() {
+		if err := http.Serve(listener, mux); err != nil {
+			log.Printf("Metrics server error: %v", err)
+		}
+	}()
+	log.Printf("Metrics server listening on %s", listener.Addr())
+	return nil
+}
+
+func countManifests(folder string) int {
+	matches, err := filepath.Glob(filepath.Join(folder, "*.json"))
+	if err != nil {
+		return 0
+	}
+	return len(matches)
+}
+
+// childRSSCollector reports the resident memory of the Chrome and ffmpeg
+// processes started by this process (directly or through node), measured at
+// scrape time.
+type childRSSCollector struct {
+	names []string
+	desc  *prometheus.Desc
+}
+
+func newChildRSSCollector(names ...string) *childRSSCollector {
+	return &childRSSCollector{
+		names: names,
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "child_process", "resident_memory_bytes"),
+			"Resident memory of child processes, summed by program.",
+			[]string{"program"}, nil,
+		),
+	}
+}
+
+func (c *childRSSCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *childRSSCollector) Collect(ch chan<- prometheus.Metric) {
+	totals := make(map[string]uint64, len(c.names))
+	for _, name := range c.names {
+		totals[name] = 0
+	}
+	self, err := process.NewProcess(int32(os.Getpid()))
+	if err == nil {
+		c.sumDescendants(self, totals)
+	}
+	for name, rss := range totals {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(rss), name)
+	}
+}
+
+func (c *childRSSCollector) sumDescendants(parent *process.Process, totals map[string]uint64) {
+	children, err := parent.Children()
+	if err != nil {
+		return
+	}
+	for _, child := range children {
+		if name, err := child.Name(); err == nil {
+			if program := c.program(name); program != "" {
+				if memory, err := child.MemoryInfo(); err == nil {
+					totals[program] += memory.RSS
+				}
+			}
+		}
+		c.sumDescendants(child, totals)
+	}
+}
+
+// program maps a process name (e.g. "chrome_crashpad", "Google Chrome Helper",
+// "ffmpeg") to the program it is counted under.
+func (c *childRSSCollector) program(processName string) string {
+	processName = strings.ToLower(processName)
+	for _, name := range c.names {
+		if strings.Contains(processName, name) {
+			return name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"errors"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandlerExposesPipelineMetrics(t *testing.T) {
+	workDir := t.TempDir()
+	t.Setenv("CODEVIDEO_WORK_DIR", workDir)
+	t.Setenv("CODEVIDEO_MAX_CONCURRENT_JOBS", "3")
+	os.MkdirAll(filepath.Join(workDir, "new"), 0755)
+	for _, name := range []string{"a.json", "b.json", "c.json"} {
+		os.WriteFile(filepath.Join(workDir, "new", name), []byte("{}"), 0644)
+	}
+
+	done := JobStarted()
+	defer done()
+	ObserveStage("recording", 90*time.Second, nil)
+	ObserveStage("upload", time.Second, errors.New("timeout"))
+	AddUploadedBytes(2048)
+
+	recorder := httptest.NewRecorder()
+	Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	body := recorder.Body.String()
+	for _, want := range []string{
+		"codevideo_jobs_in_flight 1",
+		"codevideo_jobs_max_concurrent 3",
+		"codevideo_queue_depth 2",
+		`codevideo_stage_duration_seconds_bucket{stage="recording",le="120"} 1`,
+		`codevideo_stage_failures_total{stage="upload"} 1`,
+		"codevideo_uploaded_bytes_total 2048",
+		`codevideo_child_process_resident_memory_bytes{program="chrome"}`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics output is missing %q", want)
+		}
+	}
+}
+
+func TestChildRSSCollectorMatchesProgramNames(t *testing.T) {
+	c := newChildRSSCollector("chrome", "ffmpeg")
+	for name, want := range map[string]string{
+		"chrome_crashpad_handler": "chrome",
+		"Google Chrome Helper":    "chrome",
+		"ffmpeg":                  "ffmpeg",
+		"node":                    "",
+	} {
+		if got := c.program(name); got != want {
+			t.Errorf("program(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package progress
+
+import (
+	"sync"
+	"time"
+)
+
+// Stage identifies the pipeline step a progress event belongs to.
+type Stage string
+
+const (
+	// StageQueued means the job is waiting for a worker; QueuePosition says where.
+	StageQueued    Stage = "queued"
+	StageAudio     Stage = "audio"
+	StageRecording Stage = "recording"
+	StageEncoding  Stage = "encoding"
+	StageUpload    Stage = "upload"
+	StageDone      Stage = "done"
+	StageFailed    Stage = "failed"
+	// StageInterrupted means the server shut down mid-job; the job is requeued on the next start.
+	StageInterrupted Stage = "interrupted"
+)
+
+// Finished reports whether the stage ends the job (in this process).
+func (s Stage) Finished() bool {
+	return s == StageDone || s == StageFailed || s == StageInterrupted
+}
+
+// Event is a single progress update for a job. Percent is the overall job
+// progress (0-100), not the progress within the stage. QueuePosition is the
+// 1-based position among waiting jobs and is only set in the queued stage.
+type Event struct {
+	JobUUID       string    `json:"jobUuid"`
+	Stage         Stage     `json:"stage"`
+	Percent       float64   `json:"percent"`
+	ActionIndex   int       `json:"actionIndex"`
+	TotalActions  int       `json:"totalActions"`
+	QueuePosition int       `json:"queuePosition,omitempty"`
+	Message       string    `json:"message"`
+	Time          time.Time `json:"time"`
+}
+
+// Subscriber receives every published event. Subscribers are called
+// synchronously in the publisher's goroutine, so slow work (network calls)
+// must be handed off to a goroutine by the subscriber itself.
+type Subscriber func(Event)
+
+// Bus fans progress events out to independent subscribers.
+type Bus struct {
+	mu          sync.RWMutex
+	subscribers map[int]Subscriber
+	nextID      int
+}
+
+// NewBus creates an empty event bus.
+func NewBus() *Bus {
+	return &Bus{subscribers: make(map[int]Subscriber)}
+}
+
+// Subscribe registers a subscriber and returns a function that removes it.
+func (b *Bus) Subscribe(subscriber Subscriber) func() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	id := b.nextID
+	b.nextID++
+	b.subscribers[id] = subscriber
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.subscribers, id)
+	}
+}
+
+// Publish delivers the event to all subscribers, stamping its time if unset.
+func (b *Bus) Publish(event Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	b.mu.RLock()
+	subscribers := make([]Subscriber, 0, len(b.subscribers))
+	for _, subscriber := range b.subscribers {
+		subscribers = append(subscribers, subscriber)
+	}
+	b.mu.RUnlock()
+	for _, subscriber := range subscribers {
+		subscriber(event)
+	}
+}
+
+// Default is the process-wide bus the pipeline publishes to.
+var Default = NewBus()
+
+// Subscribe registers a subscriber on the default bus.
+func Subscribe(subscriber Subscriber) func() {
+	return Default.Subscribe(subscriber)
+}
+
+// Publish sends an event on the default bus.
+func Publish(event Event) {
+	Default.Publish(event)
+}
@@ -0,0 +1,88 @@
+package progress
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBusFansOutToSubscribers(t *testing.T) {
+	bus := NewBus()
+	var first, second []Event
+	bus.Subscribe(func(e Event) { first = append(first, e) })
+	unsubscribe := bus.Subscribe(func(e Event) { second = append(second, e) })
+
+	bus.Publish(Event{JobUUID: "a", Stage: StageAudio, Percent: 5})
+	unsubscribe()
+	bus.Publish(Event{JobUUID: "a", Stage: StageRecording, Percent: 50})
+
+	if len(first) != 2 || len(second) != 1 {
+		t.Fatalf("got %d and %d events, want 2 and 1", len(first), len(second))
+	}
+	if first[0].Time.IsZero() {
+		t.Error("Publish should stamp the event time")
+	}
+}
+
+func TestTrackerKeepsLatestAndForgetsFinishedJobs(t *testing.T) {
+	tracker := NewTracker(time.Minute)
+	record := tracker.Subscriber()
+	start := time.Now()
+
+	record(Event{JobUUID: "a", Stage: StageRecording, Percent: 40, Time: start})
+	record(Event{JobUUID: "a", Stage: StageDone, Percent: 100, Time: start.Add(time.Second)})
+	record(Event{JobUUID: "b", Stage: StageRecording, Percent: 20, Time: start.Add(2 * time.Second)})
+
+	if event, ok := tracker.Get("a"); !ok || event.Stage != StageDone {
+		t.Fatalf("Get(a) = %+v, %v", event, ok)
+	}
+	if all := tracker.All(); len(all) != 2 || all[0].JobUUID != "a" {
+		t.Fatalf("All() = %+v", all)
+	}
+
+	record(Event{JobUUID: "b", Stage: StageRecording, Percent: 30, Time: start.Add(2 * time.Minute)})
+	if _, ok := tracker.Get("a"); ok {
+		t.Error("finished job should be forgotten after the retention period")
+	}
+	if _, ok := tracker.Get("b"); !ok {
+		t.Error("running job should be kept")
+	}
+}
+
+func TestSlackSubscriberPostsMilestonesInOrder(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "test")
+	posted := make(chan string, 10)
+	defer func(post func(string)) { postToSlack = post }(postToSlack)
+	postToSlack = func(message string) { posted <- message }
+
+	subscriber := NewSlackSubscriber(25)
+	for _, event := range []Event{
+		{JobUUID: "a", Stage: StageRecording, Percent: 30},
+		{JobUUID: "a", Stage: StageRecording, Percent: 80},
+		{JobUUID: "a", Stage: StageEncoding, Percent: 100},
+		{JobUUID: "a", Stage: StageUpload, Percent: 100},
+		{JobUUID: "a", Stage: StageDone, Percent: 100},
+	} {
+		subscriber(event)
+	}
+
+	want := []string{
+		"TEST: CodeVideo job a progress: 25% complete",
+		"TEST: CodeVideo job a progress: 75% complete",
+		"TEST: CodeVideo job a complete",
+	}
+	for _, message := range want {
+		select {
+		case got := <-posted:
+			if got != message {
+				t.Errorf("posted %q, want %q", got, message)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("%q was never posted", message)
+		}
+	}
+	select {
+	case got := <-posted:
+		t.Errorf("unexpected post %q", got)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
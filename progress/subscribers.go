@@ -0,0 +1,83 @@
+package progress
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	slack "github.com/codevideo/go-utils/slack"
+)
+
+// LogSubscriber writes every event to the debug log.
+func LogSubscriber(event Event) {
+	log.Debugf("Progress for job %s: stage=%s %.1f%% action %d/%d %s",
+		event.JobUUID, event.Stage, event.Percent, event.ActionIndex, event.TotalActions, event.Message)
+}
+
+// slackQueueSize is how many Slack posts may wait for the worker before new
+// ones are dropped.
+const slackQueueSize = 100
+
+// postToSlack sends a message to Slack; tests replace it.
+var postToSlack = func(message string) { slack.SendSlackMessage(message) }
+
+// NewSlackSubscriber returns a subscriber that posts to Slack only when a job
+// crosses a milestone (every milestonePercent percent, below 100), finishes or
+// fails. Posts are sent by a single worker so they arrive in order.
+func NewSlackSubscriber(milestonePercent float64) Subscriber {
+	if milestonePercent <= 0 {
+		milestonePercent = 25
+	}
+	var mu sync.Mutex
+	lastMilestone := make(map[string]float64)
+
+	posts := make(chan string, slackQueueSize)
+	go func() {
+		for message := range posts {
+			postToSlack(message)
+		}
+	}()
+
+	return func(event Event) {
+		environment := strings.ToUpper(os.Getenv("ENVIRONMENT"))
+		var message string
+
+		mu.Lock()
+		switch event.Stage {
+		case StageDone:
+			delete(lastMilestone, event.JobUUID)
+			message = fmt.Sprintf("%s: CodeVideo job %s complete", environment, event.JobUUID)
+			if event.Message != "" {
+				message += ": " + event.Message
+			}
+		case StageFailed:
+			delete(lastMilestone, event.JobUUID)
+			message = fmt.Sprintf("%s: CodeVideo job %s failed: %s", environment, event.JobUUID, event.Message)
+		case StageInterrupted:
+			delete(lastMilestone, event.JobUUID)
+			message = fmt.Sprintf("%s: CodeVideo job %s interrupted by shutdown; it will be requeued on the next start", environment, event.JobUUID)
+		default:
+			milestone := math.Floor(event.Percent/milestonePercent) * milestonePercent
+			previous, seen := lastMilestone[event.JobUUID]
+			// 100% is announced by the "complete" post
+			if milestone > 0 && milestone < 100 && (!seen || milestone > previous) {
+				lastMilestone[event.JobUUID] = milestone
+				message = fmt.Sprintf("%s: CodeVideo job %s progress: %.0f%% complete", environment, event.JobUUID, milestone)
+			}
+		}
+		mu.Unlock()
+
+		if message != "" {
+			// never block the pipeline on the network
+			select {
+			case posts <- message:
+			default:
+				log.Printf("Dropping Slack message, %d are already waiting: %s", slackQueueSize, message)
+			}
+		}
+	}
+}
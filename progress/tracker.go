@@ -0,0 +1,63 @@
+package progress
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Tracker keeps the latest event of every job so it can be queried, e.g. by
+// the serve-mode status API. Finished and failed jobs are forgotten after the
+// retention period.
+type Tracker struct {
+	mu        sync.RWMutex
+	latest    map[string]Event
+	retention time.Duration
+}
+
+// NewTracker creates a tracker that keeps finished jobs for retention.
+func NewTracker(retention time.Duration) *Tracker {
+	return &Tracker{
+		latest:    make(map[string]Event),
+		retention: retention,
+	}
+}
+
+// Subscriber returns the tracker's subscriber for registering on a bus.
+func (t *Tracker) Subscriber() Subscriber {
+	return t.record
+}
+
+func (t *Tracker) record(event Event) {
+	if event.JobUUID == "" {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.latest[event.JobUUID] = event
+	for uuid, previous := range t.latest {
+		if previous.Stage.Finished() && event.Time.Sub(previous.Time) > t.retention {
+			delete(t.latest, uuid)
+		}
+	}
+}
+
+// Get returns the latest event for a job.
+func (t *Tracker) Get(uuid string) (Event, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	event, ok := t.latest[uuid]
+	return event, ok
+}
+
+// All returns the latest event of every tracked job, oldest update first.
+func (t *Tracker) All() []Event {
+	t.mu.RLock()
+	events := make([]Event, 0, len(t.latest))
+	for _, event := range t.latest {
+		events = append(events, event)
+	}
+	t.mu.RUnlock()
+	sort.Slice(events, func(i, j int) bool { return events[i].Time.Before(events[j].Time) })
+	return events
+}
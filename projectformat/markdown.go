@@ -0,0 +1,398 @@
+package projectformat
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/codevideo/codevideo-cli/types"
+	"gopkg.in/yaml.v3"
+)
+
+// Action names of the Markdown building blocks.
+const (
+	speakAction      = "author-speak-before"
+	typeAction       = "editor-type"
+	createFileAction = "file-explorer-create-file"
+	openFileAction   = "file-explorer-open-file"
+)
+
+// actionsFence is the info string of code blocks holding a YAML list of
+// actions that have no Markdown form.
+const actionsFence = "codevideo"
+
+var headingPattern = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+
+// cutFrontMatter splits a document starting with a --- line into the YAML
+// before the next --- line and the rest.
+func cutFrontMatter(text string) (frontMatter string, body string, ok bool) {
+	rest, ok := strings.CutPrefix(text, "---\n")
+	if !ok {
+		return "", text, false
+	}
+	if strings.HasPrefix(rest, "---\n") || rest == "---" {
+		return "", strings.TrimPrefix(rest, "---"), true
+	}
+	end := strings.Index(rest, "\n---\n")
+	if end < 0 {
+		if !strings.HasSuffix(rest, "\n---") {
+			return "", text, false
+		}
+		end = len(rest) - len("\n---")
+	}
+	body = rest[end+len("\n---"):]
+	return rest[:end+1], strings.TrimPrefix(body, "\n"), true
+}
+
+// parseMarkdown reads a Markdown lesson. Front matter holds the lesson's
+// other properties; without front matter the first # heading names it, and
+// with empty front matter (or neither) the document is a list of actions. Paragraphs are spoken
+// with author-speak-before, code blocks are typed with editor-type (into
+// file=PATH, created and opened first, when given), and ```codevideo blocks
+// hold YAML lists of any other actions. Other headings are ignored.
+func parseMarkdown(text string) (*yaml.Node, error) {
+	text = normalizeNewlines(text)
+	frontMatter, body, hasFrontMatter := cutFrontMatter(text)
+	lineOffset := 0
+	if hasFrontMatter {
+		lineOffset = strings.Count(text[:len(text)-len(body)], "\n")
+	}
+
+	var lesson *yaml.Node
+	if hasFrontMatter && strings.TrimSpace(frontMatter) != "" {
+		var document yaml.Node
+		if err := yaml.Unmarshal([]byte(frontMatter), &document); err != nil {
+			return nil, fmt.Errorf("front matter: %w", err)
+		}
+		lesson = &yaml.Node{Kind: yaml.MappingNode}
+		if len(document.Content) > 0 {
+			if document.Content[0].Kind != yaml.MappingNode {
+				return nil, fmt.Errorf("front matter must be a mapping of lesson properties")
+			}
+			lesson = document.Content[0]
+		}
+		if lookup(lesson, "actions") != nil {
+			return nil, fmt.Errorf("front matter can't set actions; write them in the document")
+		}
+	}
+
+	var actions []types.Action
+	seen := map[string]bool{}
+	var paragraph []string
+	flush := func() {
+		if len(paragraph) > 0 {
+			actions = append(actions, types.Action{Name: speakAction, Value: strings.Join(paragraph, " ")})
+			paragraph = nil
+		}
+	}
+
+	lines := strings.Split(body, "\n")
+	for i := 0; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		switch {
+		case strings.HasPrefix(trimmed, "```"):
+			flush()
+			start := i + lineOffset + 1
+			fence := trimmed[:len(trimmed)-len(strings.TrimLeft(trimmed, "`"))]
+			info := strings.TrimSpace(trimmed[len(fence):])
+			var code []string
+			closed := false
+			for i++; i < len(lines); i++ {
+				closing := strings.TrimSpace(lines[i])
+				if strings.HasPrefix(closing, fence) && strings.Trim(closing, "`") == "" {
+					closed = true
+					break
+				}
+				code = append(code, lines[i])
+			}
+			if !closed {
+				return nil, fmt.Errorf("line %d: code block is never closed", start)
+			}
+			blockActions, err := codeBlock(info, strings.Join(code, "\n"), seen)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", start, err)
+			}
+			actions = append(actions, blockActions...)
+
+		case trimmed == "":
+			flush()
+
+		case headingPattern.MatchString(trimmed):
+			flush()
+			match := headingPattern.FindStringSubmatch(trimmed)
+			if lesson == nil && !hasFrontMatter && match[1] == "#" {
+				lesson = &yaml.Node{Kind: yaml.MappingNode}
+				lesson.Content = append(lesson.Content, stringNode("name"), stringNode(match[2]))
+			}
+
+		default:
+			paragraph = append(paragraph, trimmed)
+		}
+	}
+	flush()
+
+	actionsNode, err := actionsToNode(actions)
+	if err != nil {
+		return nil, err
+	}
+	if lesson == nil {
+		return actionsNode, nil
+	}
+	lesson.Content = append(lesson.Content, stringNode("actions"), actionsNode)
+	return lesson, nil
+}
+
+// codeBlock returns the actions of a code block with the given info string,
+// e.g. "js file=src/index.js".
+func codeBlock(info string, code string, seen map[string]bool) ([]types.Action, error) {
+	fields := strings.Fields(info)
+	if len(fields) > 0 && fields[0] == actionsFence {
+		actions, err := parseActionList(code)
+		for _, action := range actions {
+			markSeen(action, seen)
+		}
+		return actions, err
+	}
+
+	var file string
+	for i, field := range fields {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			if i == 0 {
+				continue // the language
+			}
+			return nil, fmt.Errorf("unexpected %q in the code block info (use file=PATH)", field)
+		}
+		switch key {
+		case "file":
+			file = strings.Trim(value, `"'`)
+		default:
+			return nil, fmt.Errorf("unknown code block attribute %q (use file=PATH)", key)
+		}
+	}
+
+	var actions []types.Action
+	if file != "" {
+		if !seen[file] {
+			actions = append(actions, types.Action{Name: createFileAction, Value: file})
+			seen[file] = true
+		}
+		actions = append(actions, types.Action{Name: openFileAction, Value: file})
+	}
+	if code != "" {
+		actions = append(actions, types.Action{Name: typeAction, Value: code})
+	}
+	return actions, nil
+}
+
+// parseActionList reads a YAML list of actions. Values may be written as
+// numbers or booleans, e.g. value: 1.
+func parseActionList(code string) ([]types.Action, error) {
+	var items []struct {
+		Name  string    `yaml:"name"`
+		Value yaml.Node `yaml:"value"`
+	}
+	if err := yaml.Unmarshal([]byte(code), &items); err != nil {
+		return nil, fmt.Errorf("%s block: %w", actionsFence, err)
+	}
+	actions := make([]types.Action, len(items))
+	for i, item := range items {
+		if item.Name == "" || item.Value.Kind != yaml.ScalarNode {
+			return nil, fmt.Errorf("%s block: action %d needs a name and a value", actionsFence, i+1)
+		}
+		actions[i] = types.Action{Name: item.Name, Value: item.Value.Value}
+	}
+	return actions, nil
+}
+
+// markSeen records the files an action creates or opens.
+func markSeen(action types.Action, seen map[string]bool) {
+	if action.Name == createFileAction || action.Name == openFileAction {
+		seen[action.Value] = true
+	}
+}
+
+// renderMarkdown writes a lesson or a list of actions as Markdown, the
+// inverse of parseMarkdown. Lists of actions start with empty front matter so
+// they're recognized as Markdown without an extension; actions that parseMarkdown would read back
+// differently are kept in ```codevideo blocks.
+func renderMarkdown(root *yaml.Node) ([]byte, error) {
+	var out bytes.Buffer
+	actionsNode := root
+	if root.Kind == yaml.MappingNode {
+		if lookup(root, "lessons") != nil {
+			return nil, fmt.Errorf("a course can't be written as Markdown, which holds a single lesson; convert its lessons to Markdown one at a time")
+		}
+		actionsNode = lookup(root, "actions")
+		if actionsNode == nil {
+			return nil, fmt.Errorf("only lessons and lists of actions can be written as Markdown")
+		}
+		frontMatter := &yaml.Node{Kind: yaml.MappingNode}
+		for i := 0; i+1 < len(root.Content); i += 2 {
+			if root.Content[i].Value != "actions" {
+				frontMatter.Content = append(frontMatter.Content, root.Content[i], root.Content[i+1])
+			}
+		}
+		data, err := encodeYAML(frontMatter)
+		if err != nil {
+			return nil, err
+		}
+		out.WriteString("---\n")
+		out.Write(data)
+		out.WriteString("---\n")
+	} else {
+		// empty front matter marks a list of actions as Markdown
+		out.WriteString("---\n---\n")
+	}
+	if actionsNode.Kind != yaml.SequenceNode {
+		return nil, fmt.Errorf("only lessons and lists of actions can be written as Markdown")
+	}
+
+	var actions []types.Action
+	var data bytes.Buffer
+	if err := writeJSON(&data, actionsNode); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data.Bytes(), &actions); err != nil {
+		return nil, fmt.Errorf("invalid actions: %w", err)
+	}
+
+	var blocks []string
+	var pending []types.Action
+	flush := func() error {
+		if len(pending) == 0 {
+			return nil
+		}
+		data, err := yaml.Marshal(pending)
+		if err != nil {
+			return err
+		}
+		blocks = append(blocks, fenced(actionsFence, strings.TrimSuffix(string(data), "\n")))
+		pending = nil
+		return nil
+	}
+
+	seen := map[string]bool{}
+	for i := 0; i < len(actions); i++ {
+		action := actions[i]
+		next := func(offset int, name string) bool {
+			return i+offset < len(actions) && actions[i+offset].Name == name && (name != openFileAction || actions[i+offset].Value == action.Value)
+		}
+		var block string
+		consumed := 1
+		switch {
+		case action.Name == speakAction && speakable(action.Value):
+			block = action.Value
+		case action.Name == typeAction && action.Value != "":
+			block = fenced(language(""), action.Value)
+		case action.Name == createFileAction && !seen[action.Value] && fileAttribute(action.Value) && next(1, openFileAction) && next(2, typeAction) && actions[i+2].Value != "":
+			block = fenced(language(action.Value)+" file="+action.Value, actions[i+2].Value)
+			consumed = 3
+		case action.Name == openFileAction && seen[action.Value] && fileAttribute(action.Value) && next(1, typeAction) && actions[i+1].Value != "":
+			block = fenced(language(action.Value)+" file="+action.Value, actions[i+1].Value)
+			consumed = 2
+		}
+		markSeen(action, seen)
+		if block == "" {
+			pending = append(pending, action)
+			continue
+		}
+		if err := flush(); err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, block)
+		i += consumed - 1
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	if out.Len() > 0 && len(blocks) > 0 {
+		out.WriteString("\n")
+	}
+	out.WriteString(strings.Join(blocks, "\n\n"))
+	if len(blocks) > 0 {
+		out.WriteString("\n")
+	}
+	return out.Bytes(), nil
+}
+
+// speakable reports whether text reads back as the same paragraph.
+func speakable(text string) bool {
+	return text != "" && text == strings.TrimSpace(text) && !strings.Contains(text, "\n") &&
+		!strings.HasPrefix(text, "```") && !headingPattern.MatchString(text) && text != "---"
+}
+
+// fileAttribute reports whether path can be written as file=PATH.
+func fileAttribute(path string) bool {
+	return path != "" && !strings.ContainsAny(path, " \t\"'`")
+}
+
+// fenced writes code in a code block with a fence longer than any run of
+// backticks in it.
+func fenced(info string, code string) string {
+	fence := "```"
+	for _, line := range strings.Split(code, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if run := len(trimmed) - len(strings.TrimLeft(trimmed, "`")); run >= len(fence) {
+			fence = strings.Repeat("`", run+1)
+		}
+	}
+	return strings.TrimSpace(fence+info) + "\n" + code + "\n" + fence
+}
+
+// languages are the code block languages of common file extensions.
+var languages = map[string]string{
+	".js":   "js",
+	".jsx":  "jsx",
+	".ts":   "ts",
+	".tsx":  "tsx",
+	".py":   "python",
+	".go":   "go",
+	".rs":   "rust",
+	".rb":   "ruby",
+	".sh":   "shell",
+	".json": "json",
+	".yaml": "yaml",
+	".yml":  "yaml",
+	".md":   "markdown",
+	".html": "html",
+	".css":  "css",
+}
+
+// language returns the code block language of a file, or "" when unknown.
+func language(path string) string {
+	return languages[strings.ToLower(filepath.Ext(path))]
+}
+
+// lookup returns the value of key in a mapping node.
+func lookup(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func stringNode(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
+
+func actionsToNode(actions []types.Action) (*yaml.Node, error) {
+	if actions == nil {
+		actions = []types.Action{}
+	}
+	data, err := json.Marshal(actions)
+	if err != nil {
+		return nil, err
+	}
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	return document.Content[0], nil
+}
@@ -0,0 +1,343 @@
+// Package projectformat converts projects (actions, lessons and courses)
+// between the JSON the player reads and the YAML and Markdown formats they
+// can be written in. Key order is kept in every direction.
+package projectformat
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/codevideo/codevideo-cli/types"
+	"gopkg.in/yaml.v3"
+)
+
+// Format is a project file format.
+type Format string
+
+// Project formats.
+const (
+	JSON     Format = "json"
+	YAML     Format = "yaml"
+	Markdown Format = "markdown"
+)
+
+// Formats are the supported formats.
+var Formats = []Format{JSON, YAML, Markdown}
+
+// Parse returns the format with the given name or file extension, e.g.
+// "yml" or "md".
+func Parse(name string) (Format, error) {
+	switch strings.ToLower(strings.TrimPrefix(name, ".")) {
+	case "json":
+		return JSON, nil
+	case "yaml", "yml":
+		return YAML, nil
+	case "markdown", "md":
+		return Markdown, nil
+	}
+	return "", fmt.Errorf("unknown format %q (use json, yaml or markdown)", name)
+}
+
+// ForPath returns the format of a file from its extension.
+func ForPath(path string) (Format, bool) {
+	ext := filepath.Ext(path)
+	if ext == "" {
+		return "", false
+	}
+	format, err := Parse(ext)
+	return format, err == nil
+}
+
+// Sniff guesses the format of a project: JSON when it starts with { or [,
+// Markdown when it has a code fence or front matter, YAML when it parses as a
+// YAML mapping or list, and Markdown when it has a # heading. Anything else
+// isn't a project.
+func Sniff(data []byte) (Format, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+		return JSON, nil
+	}
+	text := normalizeNewlines(string(data))
+	lines := strings.Split(text, "\n")
+	for _, line := range lines {
+		if strings.HasPrefix(line, "```") {
+			return Markdown, nil
+		}
+	}
+	if frontMatter, body, ok := cutFrontMatter(text); ok && (strings.TrimSpace(body) != "" || strings.TrimSpace(frontMatter) == "") {
+		return Markdown, nil
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err == nil && len(node.Content) == 1 {
+		if kind := node.Content[0].Kind; kind == yaml.MappingNode || kind == yaml.SequenceNode {
+			return YAML, nil
+		}
+	}
+	for _, line := range lines {
+		if headingPattern.MatchString(strings.TrimSpace(line)) {
+			return Markdown, nil
+		}
+	}
+	if len(trimmed) == 0 {
+		return "", fmt.Errorf("the project is empty")
+	}
+	return "", fmt.Errorf("not a JSON, YAML or Markdown project: it isn't a JSON or YAML object or list, and has no Markdown front matter, heading or code block")
+}
+
+// Normalize returns a project in any format as JSON, sniffing its format.
+func Normalize(data []byte) ([]byte, error) {
+	format, err := Sniff(data)
+	if err != nil {
+		return nil, err
+	}
+	return ToJSON(data, format)
+}
+
+// ToJSON converts a project in the given format to indented JSON.
+func ToJSON(data []byte, from Format) ([]byte, error) {
+	switch from {
+	case JSON:
+		var out bytes.Buffer
+		if err := json.Indent(&out, data, "", "  "); err != nil {
+			return nil, fmt.Errorf("invalid project JSON: %w", err)
+		}
+		out.WriteByte('\n')
+		return out.Bytes(), nil
+	case YAML:
+		var document yaml.Node
+		if err := yaml.Unmarshal(data, &document); err != nil {
+			return nil, fmt.Errorf("invalid project YAML: %w", err)
+		}
+		if len(document.Content) == 0 {
+			return nil, fmt.Errorf("invalid project YAML: the document is empty")
+		}
+		actionValuesAsStrings(document.Content[0], true)
+		return nodeToJSON(&document)
+	case Markdown:
+		node, err := parseMarkdown(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("invalid project Markdown: %w", err)
+		}
+		return nodeToJSON(node)
+	}
+	return nil, fmt.Errorf("unknown format %q", from)
+}
+
+// FromJSON converts a JSON project to the given format.
+func FromJSON(data []byte, to Format) ([]byte, error) {
+	if to == JSON {
+		return ToJSON(data, JSON)
+	}
+	if !json.Valid(data) {
+		_, err := ToJSON(data, JSON)
+		return nil, err
+	}
+	var document yaml.Node
+	// JSON is YAML, so this keeps the key order
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("invalid project JSON: %w", err)
+	}
+	root := document.Content[0]
+	switch to {
+	case YAML:
+		return encodeYAML(root)
+	case Markdown:
+		return renderMarkdown(root)
+	}
+	return nil, fmt.Errorf("unknown format %q", to)
+}
+
+// Convert converts a project between formats.
+func Convert(data []byte, from Format, to Format) ([]byte, error) {
+	if from == to {
+		return data, nil
+	}
+	jsonData, err := ToJSON(data, from)
+	if err != nil {
+		return nil, err
+	}
+	return FromJSON(jsonData, to)
+}
+
+// InsertActions inserts actions into a project in the given format before
+// index, or after its last action when index is negative. The project is a
+// lesson or a list of actions; its other properties, their order and its
+// format are kept.
+func InsertActions(data []byte, format Format, index int, actions []types.Action) ([]byte, error) {
+	var root *yaml.Node
+	switch format {
+	case JSON, YAML:
+		if format == JSON && !json.Valid(data) {
+			_, err := ToJSON(data, JSON)
+			return nil, err
+		}
+		var document yaml.Node
+		if err := yaml.Unmarshal(data, &document); err != nil {
+			return nil, fmt.Errorf("invalid project %s: %w", strings.ToUpper(string(format)), err)
+		}
+		if len(document.Content) == 0 {
+			return nil, fmt.Errorf("the project is empty")
+		}
+		root = document.Content[0]
+	case Markdown:
+		var err error
+		if root, err = parseMarkdown(string(data)); err != nil {
+			return nil, fmt.Errorf("invalid project Markdown: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+
+	sequence := root
+	if root.Kind == yaml.MappingNode {
+		if lookup(root, "lessons") != nil {
+			return nil, fmt.Errorf("the project is a course; insert the actions into one of its lessons")
+		}
+		sequence = lookup(root, "actions")
+	}
+	if sequence == nil || sequence.Kind != yaml.SequenceNode {
+		return nil, fmt.Errorf("the project is neither a lesson nor a list of actions")
+	}
+	if index < 0 {
+		index = len(sequence.Content)
+	}
+	if index > len(sequence.Content) {
+		return nil, fmt.Errorf("index %d is out of range; the project has %d actions", index, len(sequence.Content))
+	}
+	inserted, err := actionsToNode(actions)
+	if err != nil {
+		return nil, err
+	}
+	sequence.Content = append(sequence.Content[:index], append(inserted.Content, sequence.Content[index:]...)...)
+
+	switch format {
+	case JSON:
+		return nodeToJSON(root)
+	case YAML:
+		return encodeYAML(root)
+	}
+	return renderMarkdown(root)
+}
+
+// encodeYAML writes node in block style, with multi-line strings as literal
+// block scalars so code stays readable.
+func encodeYAML(node *yaml.Node) ([]byte, error) {
+	blockStyle(node)
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(node); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	if node.Kind == yaml.ScalarNode && node.ShortTag() == "!!str" && strings.Contains(node.Value, "\n") {
+		node.Style = yaml.LiteralStyle
+	}
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
+
+// actionValuesAsStrings tags the action values in node written as numbers or
+// booleans, e.g. value: 1, as strings, which is what the player expects.
+// Actions are looked for in node when it is a list of actions, and in the
+// actions of lessons and courses.
+func actionValuesAsStrings(node *yaml.Node, isActions bool) {
+	switch node.Kind {
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			if !isActions {
+				actionValuesAsStrings(item, false)
+				continue
+			}
+			if value := lookup(item, "value"); value != nil && value.Kind == yaml.ScalarNode && value.ShortTag() != "!!null" {
+				value.Tag = "!!str"
+			}
+		}
+	case yaml.MappingNode:
+		if actions := lookup(node, "actions"); actions != nil {
+			actionValuesAsStrings(actions, true)
+		}
+		if lessons := lookup(node, "lessons"); lessons != nil {
+			actionValuesAsStrings(lessons, false)
+		}
+	}
+}
+
+// nodeToJSON writes a YAML node as indented JSON.
+func nodeToJSON(node *yaml.Node) ([]byte, error) {
+	var compact bytes.Buffer
+	if err := writeJSON(&compact, node); err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, compact.Bytes(), "", "  "); err != nil {
+		return nil, err
+	}
+	out.WriteByte('\n')
+	return out.Bytes(), nil
+}
+
+func writeJSON(out *bytes.Buffer, node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		return writeJSON(out, node.Content[0])
+	case yaml.AliasNode:
+		return writeJSON(out, node.Alias)
+	case yaml.MappingNode:
+		out.WriteByte('{')
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if i > 0 {
+				out.WriteByte(',')
+			}
+			key, _ := json.Marshal(node.Content[i].Value)
+			out.Write(key)
+			out.WriteByte(':')
+			if err := writeJSON(out, node.Content[i+1]); err != nil {
+				return err
+			}
+		}
+		out.WriteByte('}')
+	case yaml.SequenceNode:
+		out.WriteByte('[')
+		for i, item := range node.Content {
+			if i > 0 {
+				out.WriteByte(',')
+			}
+			if err := writeJSON(out, item); err != nil {
+				return err
+			}
+		}
+		out.WriteByte(']')
+	case yaml.ScalarNode:
+		var value interface{} = node.Value
+		switch node.ShortTag() {
+		case "!!null":
+			value = nil
+		case "!!bool", "!!int", "!!float":
+			if err := node.Decode(&value); err != nil {
+				return err
+			}
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("line %d: %q can't be written as JSON: %w", node.Line, node.Value, err)
+		}
+		out.Write(data)
+	}
+	return nil
+}
+
+func normalizeNewlines(text string) string {
+	return strings.ReplaceAll(text, "\r\n", "\n")
+}
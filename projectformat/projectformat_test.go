@@ -0,0 +1,205 @@
+package projectformat
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/codevideo/codevideo-cli/types"
+)
+
+func TestConvertRoundTrips(t *testing.T) {
+	paths, err := filepath.Glob("../data/*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range paths {
+		if filepath.Base(path) == "config.json" {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var want interface{}
+		if err := json.Unmarshal(data, &want); err != nil {
+			t.Fatal(err)
+		}
+		object, _ := want.(map[string]interface{})
+		_, isCourse := object["lessons"]
+		for _, format := range []Format{YAML, Markdown} {
+			if isCourse && format == Markdown {
+				continue
+			}
+			converted, err := FromJSON(data, format)
+			if err != nil {
+				t.Fatalf("%s to %s: %v", path, format, err)
+			}
+			if sniffed, err := Sniff(converted); sniffed != format {
+				t.Errorf("%s as %s sniffed as %s (%v)", path, format, sniffed, err)
+			}
+			back, err := ToJSON(converted, format)
+			if err != nil {
+				t.Fatalf("%s from %s: %v\n%s", path, format, err, converted)
+			}
+			var got interface{}
+			if err := json.Unmarshal(back, &got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("%s changed in a round trip through %s:\n%s", path, format, converted)
+			}
+		}
+	}
+}
+
+func TestMarkdownLesson(t *testing.T) {
+	markdown := "# Hello, JavaScript\n" +
+		"\n" +
+		"Let's write our first program.\n" +
+		"It logs a greeting.\n" +
+		"\n" +
+		"```js file=src/index.js\n" +
+		"console.log(\"Hello!\");\n" +
+		"```\n" +
+		"\n" +
+		"## Running it\n" +
+		"\n" +
+		"```codevideo\n" +
+		"- name: terminal-type\n" +
+		"  value: node src/index.js\n" +
+		"- name: terminal-enter\n" +
+		"  value: 1\n" +
+		"```\n" +
+		"\n" +
+		"```js file=src/index.js\n" +
+		"// done\n" +
+		"```\n"
+	if format, err := Sniff([]byte(markdown)); format != Markdown {
+		t.Fatalf("sniffed as %s (%v)", format, err)
+	}
+	data, err := ToJSON([]byte(markdown), Markdown)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lesson types.Lesson
+	if err := json.Unmarshal(data, &lesson); err != nil {
+		t.Fatal(err)
+	}
+	want := []types.Action{
+		{Name: "author-speak-before", Value: "Let's write our first program. It logs a greeting."},
+		{Name: "file-explorer-create-file", Value: "src/index.js"},
+		{Name: "file-explorer-open-file", Value: "src/index.js"},
+		{Name: "editor-type", Value: `console.log("Hello!");`},
+		{Name: "terminal-type", Value: "node src/index.js"},
+		{Name: "terminal-enter", Value: "1"},
+		{Name: "file-explorer-open-file", Value: "src/index.js"},
+		{Name: "editor-type", Value: "// done"},
+	}
+	if lesson.Name != "Hello, JavaScript" || !reflect.DeepEqual(lesson.Actions, want) {
+		t.Errorf("got %q with actions\n%+v\nwant\n%+v", lesson.Name, lesson.Actions, want)
+	}
+}
+
+func TestMarkdownActionsAreRecognized(t *testing.T) {
+	actions := []byte(`[{"name": "author-speak-before", "value": "Note: this is it"}]`)
+	markdown, err := FromJSON(actions, Markdown)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := Normalize(markdown)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []types.Action
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("%v\n%s", err, data)
+	}
+	if want := []types.Action{{Name: "author-speak-before", Value: "Note: this is it"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v from\n%s", got, markdown)
+	}
+}
+
+func TestMarkdownErrors(t *testing.T) {
+	for markdown, want := range map[string]string{
+		"# Lesson\n\n```js\nconsole.log(1)\n":         "line 3: code block is never closed",
+		"# Lesson\n\n```js path=a.js\nx\n```\n":       `line 3: unknown code block attribute "path"`,
+		"---\nname: Lesson\nactions: []\n---\n\nHi\n": "front matter can't set actions",
+	} {
+		if _, err := ToJSON([]byte(markdown), Markdown); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("error = %v, want it to contain %q", err, want)
+		}
+	}
+}
+
+func TestInsertActions(t *testing.T) {
+	imported := []types.Action{{Name: "terminal-open", Value: "1"}}
+	lesson := `{
+  "$schema": "./schema/lesson.schema.json",
+  "type": "lesson",
+  "name": "Intro",
+  "actions": [
+    {
+      "name": "author-speak-before",
+      "value": "a"
+    }
+  ],
+  "description": "kept after actions"
+}
+`
+	got, err := InsertActions([]byte(lesson), JSON, 0, imported)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Replace(lesson, "  \"actions\": [\n", "  \"actions\": [\n    {\n      \"name\": \"terminal-open\",\n      \"value\": \"1\"\n    },\n", 1)
+	if string(got) != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	got, err = InsertActions([]byte("# Lesson\n- name: author-speak-before\n  value: a\n"), YAML, -1, imported)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "# Lesson\n- name: author-speak-before\n  value: a\n- name: terminal-open\n  value: \"1\"\n"; string(got) != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	for data, want := range map[string]string{
+		`[]`:                                "index 1 is out of range; the project has 0 actions",
+		`{"name": "Course", "lessons": []}`: "the project is a course",
+		`{"name": "Intro"}`:                 "neither a lesson nor a list of actions",
+	} {
+		if _, err := InsertActions([]byte(data), JSON, 1, imported); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("error = %v, want it to contain %q", err, want)
+		}
+	}
+}
+
+func TestYAMLActionValuesAreStrings(t *testing.T) {
+	yamlLesson := []byte(`
+name: Lesson
+actions:
+  - name: editor-type
+    value: |
+      const answer = 42;
+  - name: editor-enter
+    value: 2
+`)
+	if format, err := Sniff(yamlLesson); format != YAML {
+		t.Fatalf("sniffed as %s (%v)", format, err)
+	}
+	data, err := ToJSON(yamlLesson, YAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lesson types.Lesson
+	if err := json.Unmarshal(data, &lesson); err != nil {
+		t.Fatal(err)
+	}
+	if lesson.Actions[0].Value != "const answer = 42;\n" || lesson.Actions[1].Value != "2" {
+		t.Errorf("actions = %+v", lesson.Actions)
+	}
+}
@@ -8,6 +8,28 @@ const { resolveChromeExecutable } = require('./runtimePaths');
 // define sleep helper function
 const sleep = ms => new Promise(res => setTimeout(res, ms));
 
+// Structured events for the Go side. Every event is a single stdout line made of
+// this prefix followed by a JSON object; all other output is treated as plain logs.
+const EVENT_PREFIX = '@@codevideo-event ';
+function emitEvent(type, fields = {}) {
+    process.stdout.write(`${EVENT_PREFIX}${JSON.stringify({ type, ts: Date.now(), ...fields })}\n`);
+}
+
+// Action names for action-start events, read from the manifest when available.
+function readActionNames(manifestPath) {
+    if (!manifestPath) return [];
+    try {
+        const manifest = JSON.parse(fs.readFileSync(manifestPath, 'utf8'));
+        const actions = (manifest.actions && manifest.actions.length > 0)
+            ? manifest.actions
+            : (manifest.lesson && manifest.lesson.actions) || [];
+        return actions.map(action => action.name);
+    } catch (error) {
+        emitEvent('warning', { message: `Unable to read action names from manifest: ${error.message}` });
+        return [];
+    }
+}
+
 // Parse command line arguments
 const argv = yargs(hideBin(process.argv))
   .option('uuid', {
@@ -19,15 +41,20 @@ const argv = yargs(hideBin(process.argv))
     default: 'linux',
     description: 'Operating system (linux or mac)'
   })
-  .option('resolution', {
-    type: 'string',
-    default: '1080p',
-    description: 'Video resolution (1080p or 4K)'
+  .option('width', {
+    type: 'number',
+    default: 1920,
+    description: 'Video width in pixels'
   })
-  .option('orientation', {
-    type: 'string',
-    default: 'landscape',
-    description: 'Video orientation (landscape or portrait)'
+  .option('height', {
+    type: 'number',
+    default: 1080,
+    description: 'Video height in pixels'
+  })
+  .option('frame-rate', {
+    type: 'number',
+    default: 60,
+    description: 'Video frame rate in frames per second'
   })
   .option('debug', {
     type: 'boolean',
@@ -42,23 +69,37 @@ const argv = yargs(hideBin(process.argv))
     type: 'string',
     description: 'Absolute path to the render manifest'
   })
+  .option('static-url', {
+    type: 'string',
+    default: 'http://localhost:7001',
+    description: 'Base URL of the static server serving the player'
+  })
+  .option('manifest-url', {
+    type: 'string',
+    description: 'Base URL of the manifest server, used when no manifest path is given'
+  })
   .argv;
 
-// parse uuid, resolution and orientation from command line arguments
+// The player bundle always requests its manifest from this origin; the runner
+// answers those requests from --manifest-path or redirects them to --manifest-url.
+const LEGACY_MANIFEST_ORIGIN = 'http://localhost:7000';
+
+// parse uuid, video size and frame rate from command line arguments; the CLI
+// resolves resolution presets and orientation to a width and height
 const uuid = argv.uuid;
 const os = argv.os;
-const resolution = argv.resolution;
-const orientation = argv.orientation;
+const width = argv.width;
+const height = argv.height;
+const frameRate = argv.frameRate;
 const debug = argv.debug;
 
-// set width and height based on resolution and orientation
-let width, height;
-if (resolution === '4K') {
-    width = orientation === 'landscape' ? 3840 : 2160;
-    height = orientation === 'landscape' ? 2160 : 3840;
-} else if (resolution === '1080p') {
-    width = orientation === 'landscape' ? 1920 : 1080;
-    height = orientation === 'landscape' ? 1080 : 1920;
+if (!Number.isInteger(width) || !Number.isInteger(height) || width <= 0 || height <= 0) {
+    console.error(`Invalid video size ${argv.width}x${argv.height}`);
+    process.exit(1);
+}
+if (!Number.isInteger(frameRate) || frameRate <= 0) {
+    console.error(`Invalid frame rate ${argv.frameRate}`);
+    process.exit(1);
 }
 // if no manifest is provided, exit
 if (!uuid) {
@@ -76,7 +117,7 @@ async function recordVideoV3() {
     fs.mkdirSync(path.dirname(outputWebm), { recursive: true });
     const file = fs.createWriteStream(outputWebm);
 
-    console.log("Launching browser with resolution:", width, "x", height, orientation, width === 3840 ? " (4K)" : " (1080p)");
+    console.log("Launching browser with resolution:", width, "x", height, "at", frameRate, "fps");
 
     const browser = await launch({
         dumpio: true,
@@ -101,18 +142,22 @@ async function recordVideoV3() {
 
     const page = await browser.newPage();
 
-    if (argv.manifestPath) {
-        const manifestPath = path.resolve(argv.manifestPath);
+    const manifestOrigin = argv.manifestUrl ? new URL(argv.manifestUrl).origin : null;
+    if (argv.manifestPath || (manifestOrigin && manifestOrigin !== LEGACY_MANIFEST_ORIGIN)) {
+        const manifestPath = argv.manifestPath ? path.resolve(argv.manifestPath) : null;
         await page.setRequestInterception(true);
         page.on('request', request => {
             const requestedUrl = new URL(request.url());
-            const isManifestRequest = requestedUrl.hostname === 'localhost'
-                && requestedUrl.port === '7000'
-                && requestedUrl.pathname === '/get-manifest-v3';
+            const isManifestRequest = requestedUrl.pathname === '/get-manifest-v3'
+                && (requestedUrl.origin === LEGACY_MANIFEST_ORIGIN || requestedUrl.origin === manifestOrigin);
             if (!isManifestRequest) {
                 request.continue();
                 return;
             }
+            if (!manifestPath) {
+                request.continue({ url: `${manifestOrigin}${requestedUrl.pathname}${requestedUrl.search}` });
+                return;
+            }
             try {
                 request.respond({
                     status: 200,
@@ -142,14 +187,16 @@ async function recordVideoV3() {
         console.log('BROWSER LOG:', msg.text());
     });
 
-    // Log page errors
+    // Report page errors
     page.on('pageerror', error => {
         console.log('PAGE ERROR:', error.message);
+        emitEvent('warning', { message: `Page error: ${error.message}` });
     });
 
-    // Log network failures
+    // Report network failures
     page.on('requestfailed', req => {
         console.log('REQUEST FAILED:', req.url(), req.failure().errorText);
+        emitEvent('warning', { message: `Request failed: ${req.url()} ${req.failure().errorText}` });
     });
 
     // Create a promise that resolves when the final progress update is received.
@@ -158,12 +205,29 @@ async function recordVideoV3() {
         resolveFinalProgress = resolve;
     });
 
-    // Expose __onActionProgress so that progress stats from the client are logged in Node.
-    // When a final progress update is received, we resolve the promise.
+    // Expose __onActionProgress so that progress stats from the client are reported to Go
+    // as progress and action start/end events. When a final progress update is received,
+    // we resolve the promise.
+    const actionNames = readActionNames(argv.manifestPath);
+    let currentActionIndex = null;
+    let finished = false;
     await page.exposeFunction('__onActionProgress', (progress) => {
-        console.log("Progress update:", progress);
-        // Check if this progress update indicates completion.
-        if (progress.progress === "100.0" || progress.currentAction >= progress.totalActions) {
+        if (finished) return;
+        const isFinal = progress.progress === "100.0" || progress.currentAction >= progress.totalActions;
+        if (currentActionIndex !== null && (isFinal || progress.currentAction !== currentActionIndex)) {
+            emitEvent('action-end', { index: currentActionIndex, name: actionNames[currentActionIndex] });
+        }
+        if (!isFinal && progress.currentAction !== currentActionIndex) {
+            currentActionIndex = progress.currentAction;
+            emitEvent('action-start', { index: currentActionIndex, name: actionNames[currentActionIndex] });
+        }
+        emitEvent('progress', {
+            progress: isFinal ? 100 : parseFloat(progress.progress) || 0,
+            currentAction: progress.currentAction,
+            totalActions: progress.totalActions,
+        });
+        if (isFinal) {
+            finished = true;
             resolveFinalProgress();
         }
     });
@@ -172,7 +236,7 @@ async function recordVideoV3() {
     // Navigate to the puppeteer page.
     await page.setViewport({ width: 0, height: 0 });
 
-    const url = `http://localhost:7001/v3?uuid=${uuid}`;
+    const url = `${argv.staticUrl.replace(/\/$/, '')}/v3?uuid=${uuid}`;
     console.log(`Navigating to ${url}`);
     await page.goto(url);
     console.log("Page navigated");
@@ -191,6 +255,8 @@ async function recordVideoV3() {
             minHeight: height,
             maxWidth: width,
             maxHeight: height,
+            minFrameRate: frameRate,
+            maxFrameRate: frameRate,
         },
     };
 
@@ -200,12 +266,14 @@ async function recordVideoV3() {
         video: true,
         mimeType: "video/webm", // WebM is well-supported for high-quality web video
         audioBitsPerSecond: 384000, // 384 kbps for high-quality stereo audio
-        videoBitsPerSecond: width === 3840 ?  80000000: 20000000, // 80 Mpbs for 4K; 20 Mbps (20,000 kbps) for high-quality 1080p video
-        frameSize: 16, // approx 60 FPS
+        // 20 Mbps (20,000 kbps) for high-quality 1080p60 video, scaled by pixels per second (80 Mbps for 4K60)
+        videoBitsPerSecond: Math.round(20000000 * (width * height * frameRate) / (1920 * 1080 * 60)),
+        frameSize: Math.round(1000 / frameRate), // milliseconds per frame
         videoConstraints
     });
     stream.pipe(file);
     console.log("Recording started");
+    emitEvent('recording-start');
 
     // Wait a moment before triggering start.
     await sleep(1000);
@@ -241,5 +309,6 @@ async function recordVideoV3() {
 
 recordVideoV3().catch(err => {
     console.error("Error during recording:", err);
+    emitEvent('fatal', { message: err && err.message ? err.message : String(err) });
     process.exitCode = 1;
 });
@@ -0,0 +1,122 @@
+package retry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/codevideo/codevideo-cli/types"
+)
+
+// Pipeline stages that have their own retry policy.
+const (
+	StageAudio     = "audio"
+	StageRecording = "recording"
+	StageEncoding  = "encoding"
+	StageUpload    = "upload"
+	StageNotify    = "notify"
+)
+
+// maxBackoff caps the exponential backoff between attempts.
+const maxBackoff = 5 * time.Minute
+
+// DefaultPolicies returns the built-in policy of every stage.
+func DefaultPolicies() map[string]types.RetryPolicy {
+	return map[string]types.RetryPolicy{
+		StageAudio:     {MaxAttempts: 3, BackoffMs: 2000, BackoffMultiplier: 2},
+		StageRecording: {MaxAttempts: 2, BackoffMs: 10000, BackoffMultiplier: 2}, // Chrome occasionally dies with "Target closed"
+		StageEncoding:  {MaxAttempts: 2, BackoffMs: 1000, BackoffMultiplier: 2},
+		StageUpload:    {MaxAttempts: 3, BackoffMs: 2000, BackoffMultiplier: 2},
+		StageNotify:    {MaxAttempts: 3, BackoffMs: 5000, BackoffMultiplier: 2},
+	}
+}
+
+// Policies resolves the policy of every stage: the defaults, overridden by the
+// global CODEVIDEO_RETRY_POLICY JSON (same shape as the manifest's "retry"
+// field, e.g. {"recording":{"maxAttempts":3}}), overridden per job by overrides.
+func Policies(overrides map[string]types.RetryPolicy) map[string]types.RetryPolicy {
+	policies := DefaultPolicies()
+	if v := os.Getenv("CODEVIDEO_RETRY_POLICY"); v != "" {
+		var global map[string]types.RetryPolicy
+		if err := json.Unmarshal([]byte(v), &global); err != nil {
+			log.Printf("Ignoring invalid CODEVIDEO_RETRY_POLICY: %v", err)
+		} else {
+			merge(policies, global)
+		}
+	}
+	merge(policies, overrides)
+	return policies
+}
+
+// merge applies the non-zero fields of overrides onto policies.
+func merge(policies map[string]types.RetryPolicy, overrides map[string]types.RetryPolicy) {
+	for stage, override := range overrides {
+		policy := policies[stage]
+		if override.MaxAttempts > 0 {
+			policy.MaxAttempts = override.MaxAttempts
+		}
+		if override.BackoffMs > 0 {
+			policy.BackoffMs = override.BackoffMs
+		}
+		if override.BackoffMultiplier > 0 {
+			policy.BackoffMultiplier = override.BackoffMultiplier
+		}
+		policies[stage] = policy
+	}
+}
+
+// Backoff returns the delay before the given (1-based) retry attempt.
+func Backoff(policy types.RetryPolicy, attempt int) time.Duration {
+	multiplier := policy.BackoffMultiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	delay := float64(policy.BackoffMs) * math.Pow(multiplier, float64(attempt-2)) * float64(time.Millisecond)
+	if delay > float64(maxBackoff) {
+		return maxBackoff
+	}
+	return time.Duration(delay)
+}
+
+// Do runs fn until it succeeds or the policy's attempts are exhausted, waiting
+// between attempts. Every attempt is passed to record (which may be nil). The
+// error of the last attempt is returned.
+func Do(ctx context.Context, stage string, policy types.RetryPolicy, fn func(attempt int) error, record func(types.JobAttempt)) error {
+	maxAttempts := max(policy.MaxAttempts, 1)
+	var err error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		if attempt > 1 {
+			delay := Backoff(policy, attempt)
+			log.Printf("Retrying %s stage in %s (attempt %d of %d) after: %v", stage, delay, attempt, maxAttempts, err)
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("%s stage cancelled: %w", stage, ctx.Err())
+			case <-time.After(delay):
+			}
+		}
+
+		started := time.Now()
+		err = fn(attempt)
+		if record != nil {
+			entry := types.JobAttempt{
+				Stage:      stage,
+				Attempt:    attempt,
+				StartedAt:  started,
+				DurationMs: time.Since(started).Milliseconds(),
+			}
+			if err != nil {
+				entry.Error = err.Error()
+			}
+			record(entry)
+		}
+		if err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s stage failed after %d attempt(s): %w", stage, maxAttempts, err)
+}
@@ -0,0 +1,68 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/codevideo/codevideo-cli/types"
+)
+
+func TestPoliciesLayersEnvAndManifestOverrides(t *testing.T) {
+	t.Setenv("CODEVIDEO_RETRY_POLICY", `{"recording":{"maxAttempts":4},"upload":{"backoffMs":500}}`)
+	policies := Policies(map[string]types.RetryPolicy{
+		StageRecording: {BackoffMs: 30000},
+	})
+
+	recording := policies[StageRecording]
+	if recording.MaxAttempts != 4 || recording.BackoffMs != 30000 || recording.BackoffMultiplier != 2 {
+		t.Errorf("recording policy = %+v", recording)
+	}
+	if upload := policies[StageUpload]; upload.MaxAttempts != 3 || upload.BackoffMs != 500 {
+		t.Errorf("upload policy = %+v", upload)
+	}
+	if audio := policies[StageAudio]; audio != DefaultPolicies()[StageAudio] {
+		t.Errorf("audio policy = %+v, want the default", audio)
+	}
+}
+
+func TestBackoffGrowsAndIsCapped(t *testing.T) {
+	policy := types.RetryPolicy{BackoffMs: 1000, BackoffMultiplier: 2}
+	if got := Backoff(policy, 2); got != time.Second {
+		t.Errorf("Backoff(2) = %s", got)
+	}
+	if got := Backoff(policy, 4); got != 4*time.Second {
+		t.Errorf("Backoff(4) = %s", got)
+	}
+	if got := Backoff(policy, 30); got != maxBackoff {
+		t.Errorf("Backoff(30) = %s, want the cap", got)
+	}
+}
+
+func TestDoRecordsEveryAttempt(t *testing.T) {
+	var attempts []types.JobAttempt
+	record := func(a types.JobAttempt) { attempts = append(attempts, a) }
+	policy := types.RetryPolicy{MaxAttempts: 3, BackoffMs: 1}
+
+	calls := 0
+	err := Do(context.Background(), StageUpload, policy, func(int) error {
+		calls++
+		if calls < 2 {
+			return errors.New("connection reset")
+		}
+		return nil
+	}, record)
+	if err != nil || calls != 2 {
+		t.Fatalf("Do = %v after %d calls", err, calls)
+	}
+	if len(attempts) != 2 || attempts[0].Error != "connection reset" || attempts[1].Error != "" || attempts[1].Attempt != 2 {
+		t.Errorf("attempts = %+v", attempts)
+	}
+
+	failure := errors.New("still down")
+	err = Do(context.Background(), StageUpload, policy, func(int) error { return failure }, nil)
+	if !errors.Is(err, failure) {
+		t.Errorf("Do should wrap the last error, got %v", err)
+	}
+}
@@ -0,0 +1,245 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxListedEnum is the most enum values an error message lists.
+const maxListedEnum = 10
+
+// Problem is one way a document doesn't match its type.
+type Problem struct {
+	Path    string // e.g. "speakActionAudios[0].text"; empty for the whole document
+	Message string
+}
+
+func (p Problem) String() string {
+	if p.Path == "" {
+		return p.Message
+	}
+	return p.Path + ": " + p.Message
+}
+
+// ValidationError lists every problem found in a document.
+type ValidationError struct {
+	Problems []Problem
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Problems) == 1 {
+		return e.Problems[0].String()
+	}
+	lines := make([]string, len(e.Problems))
+	for i, problem := range e.Problems {
+		lines[i] = "  - " + problem.String()
+	}
+	return fmt.Sprintf("%d problems:\n%s", len(e.Problems), strings.Join(lines, "\n"))
+}
+
+// Decode strictly decodes the JSON document data into v, a pointer. Unknown
+// fields, values of the wrong type and values breaking the jsonschema tag
+// constraints are all reported together in a *ValidationError; v is only
+// set when there are none. A top-level "$schema" property is ignored.
+func Decode(data []byte, v interface{}) error {
+	var document interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&document); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+	if object, ok := document.(map[string]interface{}); ok {
+		delete(object, "$schema")
+	}
+
+	var problems []Problem
+	check(&problems, "", document, reflect.TypeOf(v).Elem(), constraints{})
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return json.Unmarshal(data, v)
+}
+
+func check(problems *[]Problem, path string, value interface{}, t reflect.Type, c constraints) {
+	report := func(format string, args ...interface{}) {
+		*problems = append(*problems, Problem{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if c.typ == "null" {
+		if value != nil {
+			report("must be null")
+		}
+		return
+	}
+	if value == nil {
+		switch t.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Map, reflect.Interface:
+			return
+		}
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Interface {
+		return
+	}
+
+	if t == timeType {
+		s, ok := value.(string)
+		if !ok {
+			report("must be a date-time string, got %s", describe(value))
+		} else if _, err := time.Parse(time.RFC3339, s); err != nil {
+			report("must be an RFC 3339 date-time, got %q", s)
+		}
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		s, ok := value.(string)
+		if !ok {
+			report("must be a string, got %s", describe(value))
+			return
+		}
+		if len(c.enum) > 0 && !contains(c.enum, s) {
+			if len(c.enum) > maxListedEnum {
+				report("%q is not one of the %d allowed values (see the enum in the schema)", s, len(c.enum))
+			} else {
+				report("must be one of %s, got %q", strings.Join(c.enum, ", "), s)
+			}
+		}
+		if c.minLength != nil && utf8.RuneCountInString(s) < *c.minLength {
+			if *c.minLength == 1 {
+				report("must not be empty")
+			} else {
+				report("must be at least %d characters long", *c.minLength)
+			}
+		}
+		if c.pattern != "" {
+			if matched, err := regexp.MatchString(c.pattern, s); err == nil && !matched {
+				report("%q does not match the pattern %s", s, c.pattern)
+			}
+		}
+
+	case reflect.Bool:
+		if _, ok := value.(bool); !ok {
+			report("must be true or false, got %s", describe(value))
+		}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		number, ok := value.(json.Number)
+		if !ok {
+			report("must be a number, got %s", describe(value))
+			return
+		}
+		integer := t.Kind() != reflect.Float32 && t.Kind() != reflect.Float64
+		if _, err := number.Int64(); integer && err != nil {
+			report("must be a whole number, got %s", number)
+			return
+		}
+		n, _ := number.Float64()
+		if c.minimum != nil && n < *c.minimum {
+			report("must be at least %v, got %s", *c.minimum, number)
+		}
+		if c.maximum != nil && n > *c.maximum {
+			report("must be at most %v, got %s", *c.maximum, number)
+		}
+
+	case reflect.Slice, reflect.Array:
+		items, ok := value.([]interface{})
+		if !ok {
+			report("must be an array, got %s", describe(value))
+			return
+		}
+		for i, item := range items {
+			check(problems, fmt.Sprintf("%s[%d]", path, i), item, t.Elem(), constraints{})
+		}
+
+	case reflect.Map:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			report("must be an object, got %s", describe(value))
+			return
+		}
+		for _, key := range sortedKeys(object) {
+			check(problems, join(path, key), object[key], t.Elem(), constraints{})
+		}
+
+	case reflect.Struct:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			report("must be an object, got %s", describe(value))
+			return
+		}
+		known := map[string]bool{}
+		for _, f := range fields(t) {
+			known[f.name] = true
+			fieldValue, present := object[f.name]
+			if !present {
+				if f.required {
+					*problems = append(*problems, Problem{Path: join(path, f.name), Message: "is required"})
+				}
+				continue
+			}
+			check(problems, join(path, f.name), fieldValue, f.typ, f.constraints)
+		}
+		for _, key := range sortedKeys(object) {
+			if !known[key] {
+				*problems = append(*problems, Problem{Path: join(path, key), Message: "unknown field"})
+			}
+		}
+	}
+}
+
+func join(path string, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func sortedKeys(object map[string]interface{}) []string {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// describe names the JSON type of a decoded value.
+func describe(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return fmt.Sprintf("string %q", v)
+	case bool:
+		return fmt.Sprintf("boolean %v", v)
+	case json.Number:
+		return "number " + v.String()
+	case []interface{}:
+		return "an array"
+	case map[string]interface{}:
+		return "an object"
+	}
+	return fmt.Sprintf("%T", value)
+}
@@ -0,0 +1,212 @@
+// Package schema generates JSON Schemas from Go types and strictly decodes
+// JSON against them, reporting every problem at once.
+//
+// Constraints are declared on struct fields with a jsonschema tag, a comma
+// separated list of:
+//
+//	required        the field must be present
+//	type=null       override the JSON type (for interface fields)
+//	enum=a|b|c      one of the given strings
+//	enum=@name      one of the strings registered with RegisterEnum(name, ...)
+//	minimum=N       numbers at least N
+//	maximum=N       numbers at most N
+//	minLength=N     strings at least N characters long
+//	pattern=RE      strings matching RE; must come last, it may contain commas
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Draft is the JSON Schema dialect of generated schemas.
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a JSON Schema document or subschema.
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Type                 interface{}        `json:"type,omitempty"` // a type name, or a list of them
+	Format               string             `json:"format,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+}
+
+// enums are the named value lists of enum=@name tags.
+var enums = map[string][]string{}
+
+// RegisterEnum names a list of values, for enums too long for a struct tag.
+// Register enums in init functions, before generating or decoding.
+func RegisterEnum(name string, values []string) {
+	enums[name] = values
+}
+
+// constraints are the parsed jsonschema tag of a field.
+type constraints struct {
+	required  bool
+	typ       string
+	enum      []string
+	minimum   *float64
+	maximum   *float64
+	minLength *int
+	pattern   string
+}
+
+func parseTag(tag string) constraints {
+	var c constraints
+	for tag != "" {
+		var part string
+		if strings.HasPrefix(tag, "pattern=") {
+			part, tag = tag, ""
+		} else {
+			part, tag, _ = strings.Cut(tag, ",")
+		}
+		name, value, _ := strings.Cut(part, "=")
+		switch name {
+		case "required":
+			c.required = true
+		case "type":
+			c.typ = value
+		case "enum":
+			if enumName, ok := strings.CutPrefix(value, "@"); ok {
+				c.enum = enums[enumName]
+			} else {
+				c.enum = strings.Split(value, "|")
+			}
+		case "minimum":
+			if n, err := strconv.ParseFloat(value, 64); err == nil {
+				c.minimum = &n
+			}
+		case "maximum":
+			if n, err := strconv.ParseFloat(value, 64); err == nil {
+				c.maximum = &n
+			}
+		case "minLength":
+			if n, err := strconv.Atoi(value); err == nil {
+				c.minLength = &n
+			}
+		case "pattern":
+			c.pattern = value
+		}
+	}
+	return c
+}
+
+// field is a JSON property of a struct.
+type field struct {
+	name string
+	typ  reflect.Type
+	constraints
+}
+
+// fields returns the JSON properties of a struct type, in declaration order.
+func fields(t reflect.Type) []field {
+	var result []field
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		result = append(result, field{name: name, typ: f.Type, constraints: parseTag(f.Tag.Get("jsonschema"))})
+	}
+	return result
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// For returns the JSON Schema of the type of v, titled title. Objects don't
+// allow additional properties except "$schema", which editors use to find
+// the schema.
+func For(v interface{}, title string) *Schema {
+	s := generate(reflect.TypeOf(v), constraints{})
+	s.Schema = Draft
+	s.Title = title
+	if s.Properties != nil {
+		s.Properties["$schema"] = &Schema{Type: "string"}
+	}
+	return s
+}
+
+func generate(t reflect.Type, c constraints) *Schema {
+	nullable := false
+	for t.Kind() == reflect.Pointer {
+		t, nullable = t.Elem(), true
+	}
+
+	s := &Schema{Enum: c.enum, Minimum: c.minimum, Maximum: c.maximum, MinLength: c.minLength, Pattern: c.pattern}
+	var typ string
+	switch {
+	case c.typ != "":
+		typ = c.typ
+	case t == timeType:
+		typ, s.Format = "string", "date-time"
+	default:
+		switch t.Kind() {
+		case reflect.String:
+			typ = "string"
+		case reflect.Bool:
+			typ = "boolean"
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			typ = "integer"
+		case reflect.Float32, reflect.Float64:
+			typ = "number"
+		case reflect.Slice, reflect.Array:
+			typ = "array"
+			s.Items = generate(t.Elem(), constraints{})
+			// encoding/json writes nil slices as null
+			nullable = true
+		case reflect.Map:
+			typ = "object"
+			// encoding/json writes nil maps as null
+			nullable = true
+		case reflect.Struct:
+			typ = "object"
+			closed := false
+			s.AdditionalProperties = &closed
+			s.Properties = map[string]*Schema{}
+			for _, f := range fields(t) {
+				s.Properties[f.name] = generate(f.typ, f.constraints)
+				if f.required {
+					s.Required = append(s.Required, f.name)
+				}
+			}
+		}
+	}
+
+	switch {
+	case typ == "":
+		// interfaces accept any value
+	case nullable && typ != "null":
+		s.Type = []string{typ, "null"}
+	default:
+		s.Type = typ
+	}
+	return s
+}
+
+// MarshalIndent returns the schema as indented JSON with a trailing newline.
+func (s *Schema) MarshalIndent() ([]byte, error) {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
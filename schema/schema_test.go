@@ -0,0 +1,109 @@
+package schema_test
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/codevideo/codevideo-cli/schema"
+	"github.com/codevideo/codevideo-cli/types"
+)
+
+var update = flag.Bool("update", false, "rewrite the published schema files")
+
+func TestPublishedSchemasAreUpToDate(t *testing.T) {
+	for _, schemaType := range types.SchemaTypes {
+		name := schemaType.File
+		want, err := schemaType.Schema().MarshalIndent()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if *update {
+			if err := os.WriteFile(name, want, 0644); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		got, err := os.ReadFile(name)
+		if err != nil || !bytes.Equal(got, want) {
+			t.Errorf("%s is out of date; run go test ./schema -update", name)
+		}
+	}
+}
+
+func TestDecodeReportsEveryProblem(t *testing.T) {
+	config := `{
+		"$schema": "./codevideo-ide-props.schema.json",
+		"theme": "blue",
+		"mode": "playback",
+		"defaultLanguage": "go",
+		"fontSizePx": 200,
+		"keyboardTypingPauseMs": "fast",
+		"mouseColor": "red",
+		"speakActionAudios": [{"text": "hi"}],
+		"resolution": "1080p"
+	}`
+	var props types.CodeVideoIDEProps
+	err := schema.Decode([]byte(config), &props)
+	var validation *schema.ValidationError
+	if !errors.As(err, &validation) {
+		t.Fatalf("Decode error = %v, want a ValidationError", err)
+	}
+	want := []string{
+		`theme: must be one of light, dark, got "blue"`,
+		`mode: must be one of step, replay, record, got "playback"`,
+		`speakActionAudios[0].mp3Url: is required`,
+		`mouseColor: "red" does not match the pattern`,
+		`fontSizePx: must be at most 72, got 200`,
+		`keyboardTypingPauseMs: must be a number, got string "fast"`,
+		`resolution: unknown field`,
+	}
+	if len(validation.Problems) != len(want) {
+		t.Errorf("got %d problems, want %d:\n%v", len(validation.Problems), len(want), err)
+	}
+	for _, w := range want {
+		if !strings.Contains(err.Error(), w) {
+			t.Errorf("error does not report %q:\n%v", w, err)
+		}
+	}
+	if props.Theme != "" {
+		t.Error("Decode set the value despite problems")
+	}
+}
+
+func TestDecodeValidConfig(t *testing.T) {
+	data, err := os.ReadFile("../data/config.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var props types.CodeVideoIDEProps
+	if err := schema.Decode(data, &props); err != nil {
+		t.Fatalf("data/config.json: %v", err)
+	}
+	if props.Theme != "dark" || props.FontSizePx == nil || *props.FontSizePx != 14 {
+		t.Errorf("decoded %+v", props)
+	}
+}
+
+func TestDecodeChecksActionNames(t *testing.T) {
+	lesson := `{"name": "Intro", "actions": [
+		{"name": "author-speak-before", "value": "Hello"},
+		{"name": "editor-typo", "value": "x"}
+	]}`
+	var decoded types.Lesson
+	err := schema.Decode([]byte(lesson), &decoded)
+	if err == nil || !strings.Contains(err.Error(), `actions[1].name: "editor-typo" is not one of the 120 allowed values`) {
+		t.Fatalf("Decode error = %v, want an unknown action name", err)
+	}
+
+	data, err := os.ReadFile("../data/lesson.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := schema.Decode(data, &decoded); err != nil {
+		t.Errorf("data/lesson.json: %v", err)
+	}
+}
@@ -0,0 +1,109 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/codevideo/codevideo-cli/health"
+	"github.com/codevideo/codevideo-cli/progress"
+)
+
+// readinessCacheTTL is how long a readiness report is reused, so frequent
+// probes don't hit the TTS and storage backends on every request.
+const readinessCacheTTL = 5 * time.Second
+
+// API serves job status and health for serve mode over HTTP.
+type API struct {
+	Mux        *http.ServeMux
+	tracker    *progress.Tracker
+	readiness  []health.Check
+	httpServer *http.Server
+
+	readinessMu   sync.Mutex
+	lastReadiness *health.Report
+}
+
+// NewAPI creates the serve-mode API backed by the given progress tracker.
+// /readyz runs the given readiness checks.
+func NewAPI(tracker *progress.Tracker, readiness []health.Check) *API {
+	api := &API{Mux: http.NewServeMux(), tracker: tracker, readiness: readiness}
+	api.Mux.HandleFunc("GET /jobs", api.listJobs)
+	api.Mux.HandleFunc("GET /jobs/{uuid}", api.getJob)
+	api.Mux.HandleFunc("GET /healthz", api.healthz)
+	api.Mux.HandleFunc("GET /readyz", api.readyz)
+	return api
+}
+
+// Start binds addr and serves the API in the background.
+func (a *API) Start(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("API address %s is unavailable: %w", addr, err)
+	}
+	a.httpServer = &http.Server{Handler: a.Mux}
+	go func() {
+		if err := a.httpServer.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.Printf("API server error: %v", err)
+		}
+	}()
+	log.Printf("API server listening on %s", listener.Addr())
+	return nil
+}
+
+// Stop closes the API server.
+func (a *API) Stop() error {
+	if a.httpServer == nil {
+		return nil
+	}
+	return a.httpServer.Close()
+}
+
+func (a *API) listJobs(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, a.tracker.All())
+}
+
+func (a *API) getJob(w http.ResponseWriter, r *http.Request) {
+	event, ok := a.tracker.Get(r.PathValue("uuid"))
+	if !ok {
+		http.Error(w, "Job not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, event)
+}
+
+// healthz reports that the process is alive.
+func (a *API) healthz(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": health.StatusOK})
+}
+
+// readyz reports whether the instance can render, with every check's status
+// and latency; it answers 503 when any check fails.
+func (a *API) readyz(w http.ResponseWriter, r *http.Request) {
+	a.readinessMu.Lock()
+	if a.lastReadiness == nil || time.Since(a.lastReadiness.CheckedAt) > readinessCacheTTL {
+		report := health.Run(r.Context(), a.readiness)
+		a.lastReadiness = &report
+	}
+	report := *a.lastReadiness
+	a.readinessMu.Unlock()
+
+	status := http.StatusOK
+	if report.Status != health.StatusOK {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, report)
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		log.Printf("Failed to write API response: %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/codevideo/codevideo-cli/types"
+)
+
+// RunnerEventPrefix marks a stdout line of the Puppeteer runner as a
+// structured event; the rest of the line is a JSON object. Any other line is
+// plain log output.
+const RunnerEventPrefix = "@@codevideo-event "
+
+// RunnerEventType is the kind of event emitted by the Puppeteer runner.
+type RunnerEventType string
+
+const (
+	RunnerEventProgress       RunnerEventType = "progress"
+	RunnerEventRecordingStart RunnerEventType = "recording-start"
+	RunnerEventActionStart    RunnerEventType = "action-start"
+	RunnerEventActionEnd      RunnerEventType = "action-end"
+	RunnerEventWarning        RunnerEventType = "warning"
+	RunnerEventFatal          RunnerEventType = "fatal"
+)
+
+// RunnerEvent is a structured event from the Puppeteer runner. Which fields
+// are set depends on the type.
+type RunnerEvent struct {
+	Type      RunnerEventType `json:"type"`
+	Timestamp int64           `json:"ts"` // unix milliseconds
+
+	// progress
+	Progress      float64 `json:"progress"`
+	CurrentAction int     `json:"currentAction"`
+	TotalActions  int     `json:"totalActions"`
+
+	// action-start / action-end
+	Index int    `json:"index"`
+	Name  string `json:"name"`
+
+	// warning / fatal
+	Message string `json:"message"`
+}
+
+// ParseRunnerEvent parses a runner stdout line. ok is false for plain log lines.
+func ParseRunnerEvent(line string) (event RunnerEvent, ok bool, err error) {
+	payload, found := strings.CutPrefix(line, RunnerEventPrefix)
+	if !found {
+		return RunnerEvent{}, false, nil
+	}
+	if err := json.Unmarshal([]byte(payload), &event); err != nil {
+		return RunnerEvent{}, true, fmt.Errorf("malformed runner event %q: %w", payload, err)
+	}
+	if event.Type == "" {
+		return RunnerEvent{}, true, fmt.Errorf("runner event without a type: %q", payload)
+	}
+	return event, true, nil
+}
+
+// actionTimer turns action start/end events into timings relative to the
+// start of the recording.
+type actionTimer struct {
+	originMs int64
+	open     map[int]types.ActionTiming
+	timings  []types.ActionTiming
+}
+
+func newActionTimer() *actionTimer {
+	return &actionTimer{open: make(map[int]types.ActionTiming)}
+}
+
+func (t *actionTimer) handle(event RunnerEvent) {
+	switch event.Type {
+	case RunnerEventRecordingStart:
+		t.originMs = event.Timestamp
+	case RunnerEventActionStart:
+		if t.originMs == 0 {
+			t.originMs = event.Timestamp
+		}
+		t.open[event.Index] = types.ActionTiming{
+			Index:   event.Index,
+			Name:    event.Name,
+			StartMs: event.Timestamp - t.originMs,
+		}
+	case RunnerEventActionEnd:
+		timing, ok := t.open[event.Index]
+		if !ok {
+			return
+		}
+		delete(t.open, event.Index)
+		timing.EndMs = event.Timestamp - t.originMs
+		t.timings = append(t.timings, timing)
+	}
+}
+
+// Timings returns the completed action timings in the order they finished.
+func (t *actionTimer) Timings() []types.ActionTiming {
+	return t.timings
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/codevideo/codevideo-cli/types"
+)
+
+func TestParseRunnerEventIgnoresPlainLogs(t *testing.T) {
+	// browser logs that mention progress must not be mistaken for progress events
+	for _, line := range []string{
+		"BROWSER LOG: progress: '12.3'",
+		"Progress update: { progress: '50.0', currentAction: 3 }",
+	} {
+		if _, ok, err := ParseRunnerEvent(line); ok || err != nil {
+			t.Errorf("ParseRunnerEvent(%q) = ok %v, err %v; want plain log", line, ok, err)
+		}
+	}
+}
+
+func TestParseRunnerEventProgress(t *testing.T) {
+	event, ok, err := ParseRunnerEvent(RunnerEventPrefix + `{"type":"progress","ts":1000,"progress":42.5,"currentAction":3,"totalActions":8}`)
+	if !ok || err != nil {
+		t.Fatalf("ok %v, err %v", ok, err)
+	}
+	if event.Type != RunnerEventProgress || event.Progress != 42.5 || event.CurrentAction != 3 || event.TotalActions != 8 {
+		t.Errorf("unexpected event %+v", event)
+	}
+}
+
+func TestParseRunnerEventRejectsMalformed(t *testing.T) {
+	for _, line := range []string{RunnerEventPrefix + "{not json", RunnerEventPrefix + `{"ts":1}`} {
+		if _, ok, err := ParseRunnerEvent(line); !ok || err == nil {
+			t.Errorf("ParseRunnerEvent(%q) = ok %v, err %v; want error", line, ok, err)
+		}
+	}
+}
+
+func TestActionTimerRelativeToRecordingStart(t *testing.T) {
+	timer := newActionTimer()
+	for _, event := range []RunnerEvent{
+		{Type: RunnerEventRecordingStart, Timestamp: 10_000},
+		{Type: RunnerEventActionStart, Timestamp: 11_000, Index: 0, Name: "author-speak-before"},
+		{Type: RunnerEventActionEnd, Timestamp: 14_500, Index: 0},
+		{Type: RunnerEventActionStart, Timestamp: 14_500, Index: 1, Name: "editor-type"},
+		{Type: RunnerEventActionEnd, Timestamp: 16_000, Index: 1},
+		{Type: RunnerEventActionEnd, Timestamp: 17_000, Index: 5}, // never started
+	} {
+		timer.handle(event)
+	}
+	want := []types.ActionTiming{
+		{Index: 0, Name: "author-speak-before", StartMs: 1000, EndMs: 4500},
+		{Index: 1, Name: "editor-type", StartMs: 4500, EndMs: 6000},
+	}
+	got := timer.Timings()
+	if len(got) != len(want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("timing %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/codevideo/codevideo-cli/progress"
+)
+
+// Priority classes, highest first. Production outranks staging, and within an
+// environment paid plans outrank free ones.
+const (
+	PriorityStagingFree = iota
+	PriorityStagingPaid
+	PriorityProductionFree
+	PriorityProductionPaid
+)
+
+// priorityAging raises a waiting job by one priority class per interval, so
+// staging and free jobs still run while higher classes keep arriving.
+const priorityAging = 10 * time.Minute
+
+// jobInfo is what the scheduler needs to know about a manifest.
+type jobInfo struct {
+	UUID     string
+	UserID   string
+	Priority int
+}
+
+// queuedJob is a manifest waiting for a worker slot.
+type queuedJob struct {
+	jobInfo
+	manifestPath string
+	enqueued     time.Time
+	seq          int
+}
+
+// scheduler runs manifests with bounded concurrency. Waiting jobs are started
+// by priority class (then arrival order), at most perUserCap at a time per
+// user, and their queue positions are published as progress events. On
+// shutdown it drains: queued manifests are handed to skip, running ones get
+// until the drain deadline before their context is cancelled.
+type scheduler struct {
+	size       int
+	perUserCap int
+	settle     time.Duration
+	classify   func(manifestPath string) jobInfo
+	process    func(ctx context.Context, manifestPath string)
+	skip       func(manifestPath string)
+	publish    func(progress.Event)
+	now        func() time.Time
+
+	jobCtx     context.Context
+	cancelJobs context.CancelFunc
+	stopping   chan struct{}
+
+	mu        sync.Mutex
+	draining  bool
+	pending   []*queuedJob
+	running   int
+	byUser    map[string]int
+	positions map[string]int
+	seq       int
+	wg        sync.WaitGroup
+}
+
+// newScheduler creates a scheduler running up to size jobs at once and up to
+// perUserCap per user (0 means no per-user cap). Each manifest waits settle
+// before it is classified so it is fully written.
+func newScheduler(size int, perUserCap int, settle time.Duration, classify func(manifestPath string) jobInfo, process func(ctx context.Context, manifestPath string), skip func(manifestPath string)) *scheduler {
+	jobCtx, cancelJobs := context.WithCancel(context.Background())
+	return &scheduler{
+		size:       max(size, 1),
+		perUserCap: perUserCap,
+		settle:     settle,
+		classify:   classify,
+		process:    process,
+		skip:       skip,
+		publish:    progress.Publish,
+		now:        time.Now,
+		jobCtx:     jobCtx,
+		cancelJobs: cancelJobs,
+		stopping:   make(chan struct{}),
+		byUser:     make(map[string]int),
+		positions:  make(map[string]int),
+	}
+}
+
+// submit queues a manifest. It returns false once the scheduler is draining.
+func (s *scheduler) submit(manifestPath string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.draining {
+		return false
+	}
+	s.wg.Add(1)
+	go s.enqueue(manifestPath)
+	return true
+}
+
+func (s *scheduler) enqueue(manifestPath string) {
+	select {
+	case <-time.After(s.settle):
+	case <-s.stopping:
+		s.skip(manifestPath)
+		s.wg.Done()
+		return
+	}
+	info := s.classify(manifestPath)
+
+	s.mu.Lock()
+	if s.draining {
+		s.mu.Unlock()
+		s.skip(manifestPath)
+		s.wg.Done()
+		return
+	}
+	s.seq++
+	s.pending = append(s.pending, &queuedJob{jobInfo: info, manifestPath: manifestPath, enqueued: s.now(), seq: s.seq})
+	events := s.dispatchLocked()
+	s.mu.Unlock()
+	s.publishAll(events)
+}
+
+func (s *scheduler) run(job *queuedJob) {
+	defer s.wg.Done()
+	s.process(s.jobCtx, job.manifestPath)
+
+	s.mu.Lock()
+	s.running--
+	if s.byUser[job.UserID]--; s.byUser[job.UserID] <= 0 {
+		delete(s.byUser, job.UserID)
+	}
+	var events []progress.Event
+	if !s.draining {
+		events = s.dispatchLocked()
+	}
+	s.mu.Unlock()
+	s.publishAll(events)
+}
+
+// dispatchLocked starts as many waiting jobs as slots and per-user caps allow
+// and returns queue position updates for the jobs still waiting.
+func (s *scheduler) dispatchLocked() []progress.Event {
+	s.sortPendingLocked()
+	for i := 0; i < len(s.pending) && s.running < s.size; {
+		job := s.pending[i]
+		if s.perUserCap > 0 && job.UserID != "" && s.byUser[job.UserID] >= s.perUserCap {
+			i++
+			continue
+		}
+		s.pending = append(s.pending[:i], s.pending[i+1:]...)
+		delete(s.positions, job.UUID)
+		s.running++
+		s.byUser[job.UserID]++
+		go s.run(job)
+	}
+
+	var events []progress.Event
+	for i, job := range s.pending {
+		position := i + 1
+		if s.positions[job.UUID] == position {
+			continue
+		}
+		s.positions[job.UUID] = position
+		events = append(events, progress.Event{
+			JobUUID:       job.UUID,
+			Stage:         progress.StageQueued,
+			QueuePosition: position,
+			Message:       fmt.Sprintf("Queued at position %d", position),
+		})
+	}
+	return events
+}
+
+// sortPendingLocked orders waiting jobs by aged priority class, then arrival.
+func (s *scheduler) sortPendingLocked() {
+	now := s.now()
+	effective := func(job *queuedJob) int {
+		return job.Priority + int(now.Sub(job.enqueued)/priorityAging)
+	}
+	sort.SliceStable(s.pending, func(i, j int) bool {
+		a, b := s.pending[i], s.pending[j]
+		if pa, pb := effective(a), effective(b); pa != pb {
+			return pa > pb
+		}
+		return a.seq < b.seq
+	})
+}
+
+func (s *scheduler) publishAll(events []progress.Event) {
+	for _, event := range events {
+		if event.JobUUID != "" {
+			s.publish(event)
+		}
+	}
+}
+
+// drain stops accepting manifests, waits up to timeout for running jobs and
+// then cancels them. It returns once every job has returned.
+func (s *scheduler) drain(timeout time.Duration) {
+	s.mu.Lock()
+	s.draining = true
+	close(s.stopping)
+	pending := s.pending
+	s.pending = nil
+	s.mu.Unlock()
+
+	for _, job := range pending {
+		s.skip(job.manifestPath)
+		s.wg.Done()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		s.cancelJobs()
+		<-done
+	}
+	s.cancelJobs()
+}
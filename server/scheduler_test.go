@@ -0,0 +1,201 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/codevideo/codevideo-cli/progress"
+)
+
+// testJobs classifies manifests named "<uuid>:<user>:<priority>".
+func testJobs(manifestPath string) jobInfo {
+	parts := strings.Split(manifestPath, ":")
+	return jobInfo{UUID: parts[0], UserID: parts[1], Priority: int(parts[2][0] - '0')}
+}
+
+// recorder processes jobs one by one, blocking each until released.
+type recorder struct {
+	mu      sync.Mutex
+	order   []string
+	started chan string
+	release chan struct{}
+}
+
+func newRecorder() *recorder {
+	return &recorder{started: make(chan string, 16), release: make(chan struct{})}
+}
+
+func (r *recorder) process(ctx context.Context, manifestPath string) {
+	uuid := testJobs(manifestPath).UUID
+	r.mu.Lock()
+	r.order = append(r.order, uuid)
+	r.mu.Unlock()
+	r.started <- uuid
+	<-r.release
+}
+
+func (r *recorder) next(t *testing.T) string {
+	t.Helper()
+	select {
+	case uuid := <-r.started:
+		return uuid
+	case <-time.After(time.Second):
+		t.Fatal("no job started")
+		return ""
+	}
+}
+
+// waitQueued waits until n jobs are waiting.
+func waitQueued(t *testing.T, s *scheduler, n int) {
+	t.Helper()
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		s.mu.Lock()
+		queued := len(s.pending)
+		s.mu.Unlock()
+		if queued == n {
+			return
+		}
+	}
+	t.Fatalf("expected %d queued jobs", n)
+}
+
+func TestSchedulerDrainWaitsForRunningJobs(t *testing.T) {
+	started := make(chan struct{})
+	var finished bool
+	s := newScheduler(1, 0, 0, testJobs, func(ctx context.Context, manifestPath string) {
+		close(started)
+		time.Sleep(50 * time.Millisecond)
+		finished = ctx.Err() == nil
+	}, func(string) {})
+
+	s.submit("a:u:0")
+	<-started
+	s.drain(time.Second)
+	if !finished {
+		t.Fatal("running job was cancelled before the drain timeout")
+	}
+	if s.submit("b:u:0") {
+		t.Fatal("submit accepted a manifest while draining")
+	}
+}
+
+func TestSchedulerDrainCancelsAfterTimeout(t *testing.T) {
+	started := make(chan struct{})
+	var cancelled bool
+	s := newScheduler(1, 0, 0, testJobs, func(ctx context.Context, manifestPath string) {
+		close(started)
+		<-ctx.Done()
+		cancelled = true
+	}, func(string) {})
+
+	s.submit("a:u:0")
+	<-started
+	s.drain(10 * time.Millisecond)
+	if !cancelled {
+		t.Fatal("job was not cancelled after the drain timeout")
+	}
+}
+
+func TestSchedulerDrainSkipsQueuedJobs(t *testing.T) {
+	r := newRecorder()
+	var mu sync.Mutex
+	var skipped []string
+	s := newScheduler(1, 0, 0, testJobs, r.process, func(manifestPath string) {
+		mu.Lock()
+		skipped = append(skipped, manifestPath)
+		mu.Unlock()
+	})
+	s.publish = func(progress.Event) {}
+
+	s.submit("running:u:0")
+	r.next(t)
+	s.submit("queued:u:0")
+	waitQueued(t, s, 1)
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		close(r.release)
+	}()
+	s.drain(time.Second)
+
+	if len(r.order) != 1 || r.order[0] != "running" {
+		t.Fatalf("processed = %v, want only running", r.order)
+	}
+	if len(skipped) != 1 || skipped[0] != "queued:u:0" {
+		t.Fatalf("skipped = %v, want queued:u:0", skipped)
+	}
+}
+
+func TestSchedulerStartsHighestPriorityFirst(t *testing.T) {
+	r := newRecorder()
+	s := newScheduler(1, 0, 0, testJobs, r.process, func(string) {})
+	var mu sync.Mutex
+	positions := make(map[string]int)
+	s.publish = func(event progress.Event) {
+		mu.Lock()
+		positions[event.JobUUID] = event.QueuePosition
+		mu.Unlock()
+	}
+
+	s.submit("first:a:0")
+	r.next(t)
+	s.submit("staging-free:b:0")
+	waitQueued(t, s, 1)
+	s.submit("prod-free:c:2")
+	waitQueued(t, s, 2)
+	s.submit("prod-paid:d:3")
+	waitQueued(t, s, 3)
+
+	mu.Lock()
+	if positions["prod-paid"] != 1 || positions["prod-free"] != 2 || positions["staging-free"] != 3 {
+		t.Errorf("queue positions = %v", positions)
+	}
+	mu.Unlock()
+
+	close(r.release)
+	for _, want := range []string{"prod-paid", "prod-free", "staging-free"} {
+		if got := r.next(t); got != want {
+			t.Fatalf("started %s, want %s", got, want)
+		}
+	}
+	s.drain(time.Second)
+}
+
+func TestSchedulerCapsJobsPerUser(t *testing.T) {
+	r := newRecorder()
+	s := newScheduler(2, 1, 0, testJobs, r.process, func(string) {})
+	s.publish = func(progress.Event) {}
+
+	s.submit("lesson-1:course-author:3")
+	r.next(t)
+	s.submit("lesson-2:course-author:3")
+	waitQueued(t, s, 1)
+	s.submit("other:someone-else:0")
+
+	// the second slot goes to the other user despite their lower priority
+	if got := r.next(t); got != "other" {
+		t.Fatalf("started %s, want other", got)
+	}
+	waitQueued(t, s, 1)
+	close(r.release)
+	if got := r.next(t); got != "lesson-2" {
+		t.Fatalf("started %s, want lesson-2", got)
+	}
+	s.drain(time.Second)
+}
+
+func TestSchedulerAgesWaitingJobs(t *testing.T) {
+	now := time.Now()
+	s := newScheduler(1, 0, 0, testJobs, nil, nil)
+	s.now = func() time.Time { return now }
+	s.pending = []*queuedJob{
+		{jobInfo: jobInfo{UUID: "old-staging", Priority: PriorityStagingFree}, enqueued: now.Add(-3 * priorityAging), seq: 1},
+		{jobInfo: jobInfo{UUID: "new-prod", Priority: PriorityProductionPaid - 1}, enqueued: now, seq: 2},
+	}
+	s.sortPendingLocked()
+	if s.pending[0].UUID != "old-staging" {
+		t.Fatalf("job waiting %s was not promoted above a new production job", 3*priorityAging)
+	}
+}
@@ -3,7 +3,7 @@ package server
 import (
 	"bufio"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -15,26 +15,41 @@ import (
 
 	log "github.com/sirupsen/logrus"
 
-	"github.com/clerk/clerk-sdk-go/v2"
-	"github.com/clerk/clerk-sdk-go/v2/user"
-	"github.com/codevideo/codevideo-cli/cli/config"
+	"github.com/codevideo/codevideo-cli/accounts"
+	"github.com/codevideo/codevideo-cli/billing"
 	"github.com/codevideo/codevideo-cli/cli/renderer"
+	"github.com/codevideo/codevideo-cli/cli/staticserver"
 	"github.com/codevideo/codevideo-cli/cloud"
 	"github.com/codevideo/codevideo-cli/constants"
 	"github.com/codevideo/codevideo-cli/files"
+	"github.com/codevideo/codevideo-cli/ledger"
 	"github.com/codevideo/codevideo-cli/mail"
+	"github.com/codevideo/codevideo-cli/metrics"
+	"github.com/codevideo/codevideo-cli/progress"
+	"github.com/codevideo/codevideo-cli/retry"
+	"github.com/codevideo/codevideo-cli/types"
 	"github.com/codevideo/codevideo-cli/utils"
 	"github.com/fsnotify/fsnotify"
+)
 
-	slack "github.com/codevideo/go-utils/slack"
+// directory looks up the users of serve-mode jobs and biller holds and
+// settles their tokens. WatchForManifestFiles sets them up from
+// CODEVIDEO_USER_DIRECTORY; CLI jobs have no users.
+var (
+	directory accounts.UserDirectory = accounts.NoopDirectory{}
+	biller                           = billing.New(directory, nil)
 )
 
 var debounceMu sync.Mutex
 var debounceMap = make(map[string]*time.Timer)
 
 // WatchForManifestFiles is used within the codevideo-api to watch for new manifest files in the 'new' folder.
-// When a new manifest file is detected, it is processed as a job.
-func WatchForManifestFiles() {
+// When a new manifest file is detected, it is processed as a job, rendered with the manifest's render
+// settings and renderDefaults where it sets none. Jobs interrupted by a previous shutdown
+// are requeued first. When ctx is cancelled (SIGTERM), no new manifests are accepted and in-flight jobs
+// get until the drain timeout to finish before they are cancelled and marked as interrupted; it returns
+// once every job has stopped.
+func WatchForManifestFiles(ctx context.Context, renderDefaults types.RenderSettings, endpoints staticserver.Endpoints) {
 	// Ensure required directories exist.
 	for _, dir := range []string{constants.NewFolder(), constants.ErrorFolder(), constants.SuccessFolder(), constants.VideoFolder()} {
 		if err := os.MkdirAll(dir, 0755); err != nil {
@@ -42,6 +57,12 @@ func WatchForManifestFiles() {
 		}
 	}
 
+	userDirectory, err := accounts.New(constants.UserDirectory(), constants.UserDirectoryFile())
+	if err != nil {
+		log.Fatal(err)
+	}
+	directory, biller = userDirectory, billing.New(userDirectory, ledger.Open(constants.LedgerFile()))
+
 	// Set up a watcher on the newFolder.
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
@@ -53,19 +74,29 @@ func WatchForManifestFiles() {
 		log.Fatal(err)
 	}
 
-	// semaphore channel to limit concurrency (overridable via CODEVIDEO_MAX_CONCURRENT_JOBS).
+	// the scheduler limits concurrency overall (CODEVIDEO_MAX_CONCURRENT_JOBS) and per user
+	// (CODEVIDEO_MAX_JOBS_PER_USER) and starts waiting jobs by priority class
 	maxConcurrentJobs := constants.MaxConcurrentJobs()
-	log.Printf("Worker concurrency limit: %d", maxConcurrentJobs)
-	semaphore := make(chan struct{}, maxConcurrentJobs)
+	maxJobsPerUser := constants.MaxJobsPerUser()
+	log.Printf("Worker concurrency limit: %d (%d per user)", maxConcurrentJobs, maxJobsPerUser)
+	// the 2 second settle delay ensures the file is fully written
+	queue := newScheduler(maxConcurrentJobs, maxJobsPerUser, 2*time.Second, classifyJob, func(jobCtx context.Context, manifestPath string) {
+		ProcessJob(jobCtx, manifestPath, "serve", "", renderDefaults, endpoints)
+	}, markInterrupted)
+
+	requeueInterruptedJobs(queue)
 
 	log.Println("Watching for new manifest files in", constants.NewFolder())
 
 	// Listen for filesystem events.
+watch:
 	for {
 		select {
+		case <-ctx.Done():
+			break watch
 		case event, ok := <-watcher.Events:
 			if !ok {
-				return
+				break watch
 			}
 			// Process only create events for new JSON files.
 			if event.Op&fsnotify.Create == fsnotify.Create {
@@ -78,14 +109,10 @@ func WatchForManifestFiles() {
 					// Set a new timer with a 500ms debounce interval.
 					debounceMap[event.Name] = time.AfterFunc(500*time.Millisecond, func() {
 						log.Printf("Detected new file: %s", event.Name)
-						// Limit concurrent processing.
-						semaphore <- struct{}{}
-						go func(filePath string) {
-							defer func() { <-semaphore }()
-							// Optional delay to ensure the file is fully written.
-							time.Sleep(2 * time.Second)
-							ProcessJob(filePath, "serve", "")
-						}(event.Name)
+						if !queue.submit(event.Name) {
+							// shutting down; leave it in the new folder for the next start
+							markInterrupted(event.Name)
+						}
 						// Clean up the timer from the map.
 						debounceMu.Lock()
 						delete(debounceMap, event.Name)
@@ -96,20 +123,88 @@ func WatchForManifestFiles() {
 			}
 		case err, ok := <-watcher.Errors:
 			if !ok {
-				return
+				break watch
 			}
 			log.Printf("Watcher error: %v", err)
 		}
 	}
+
+	// manifests still being debounced are left for the next start
+	debounceMu.Lock()
+	for name, timer := range debounceMap {
+		if timer.Stop() {
+			markInterrupted(name)
+		}
+		delete(debounceMap, name)
+	}
+	debounceMu.Unlock()
+
+	drainTimeout := constants.DrainTimeout()
+	log.Printf("Shutting down: no longer accepting manifests, draining in-flight jobs for up to %s", drainTimeout)
+	queue.drain(drainTimeout)
+	log.Printf("All jobs drained")
 }
 
-// ProcessJob reads the manifest file, calls the Puppeteer script, sends an email if successful,
-// and moves the manifest to the error or success folder.
-func ProcessJob(manifestPath string, mode string, outputPath string) {
-	// still at 10 from the audio generation step
-	if mode == "cli" {
-		renderer.RenderProgressToConsole(10, "Starting up video recording...")
+// requeueInterruptedJobs clears the interrupted flag of manifests left in the
+// new folder by a previous shutdown and processes them again.
+func requeueInterruptedJobs(queue *scheduler) {
+	paths, err := filepath.Glob(filepath.Join(constants.NewFolder(), "*.json"))
+	if err != nil {
+		log.Printf("Failed to list interrupted jobs: %v", err)
+		return
+	}
+	for _, manifestPath := range paths {
+		manifest, err := files.UnmarshalManifest(manifestPath)
+		if err != nil || !manifest.Interrupted {
+			continue
+		}
+		if err := utils.RemoveManifestField(manifestPath, "interrupted"); err != nil {
+			log.Printf("Failed to requeue interrupted job %s: %v", manifest.UUID, err)
+			continue
+		}
+		log.Printf("Requeueing job %s interrupted by the previous shutdown", manifest.UUID)
+		queue.submit(manifestPath)
 	}
+}
+
+// classifyJob reads the scheduling details of a manifest: production outranks
+// staging, and users on a paid plan outrank free ones. A manifest that can't be
+// read is scheduled at the lowest priority and fails when it is processed.
+func classifyJob(manifestPath string) jobInfo {
+	manifest, err := files.UnmarshalManifest(manifestPath)
+	if err != nil {
+		return jobInfo{UUID: strings.TrimSuffix(filepath.Base(manifestPath), ".json")}
+	}
+	info := jobInfo{UUID: manifest.UUID, UserID: manifest.UserID, Priority: PriorityStagingFree}
+	if manifest.Environment != "staging" {
+		info.Priority = PriorityProductionFree
+	}
+	if manifest.UserID == "" {
+		return info
+	}
+	account, err := directory.Account(context.Background(), manifest.Environment, manifest.UserID)
+	if err != nil {
+		log.Printf("Could not look up the plan of user %s for job %s, scheduling as free: %v", manifest.UserID, manifest.UUID, err)
+		return info
+	}
+	if account.Paid() {
+		info.Priority++
+	}
+	return info
+}
+
+// markInterrupted flags a manifest that was not (fully) processed before a
+// shutdown so the next start requeues it.
+func markInterrupted(manifestPath string) {
+	if err := utils.SetManifestField(manifestPath, "interrupted", true); err != nil {
+		log.Printf("Failed to mark %s as interrupted: %v", filepath.Base(manifestPath), err)
+	}
+}
+
+// ProcessJob reads the manifest file, calls the Puppeteer script, sends an email if successful,
+// and moves the manifest to the error or success folder. The manifest's render settings are
+// completed from renderDefaults and recorded on the manifest.
+func ProcessJob(ctx context.Context, manifestPath string, mode string, outputPath string, renderDefaults types.RenderSettings, endpoints staticserver.Endpoints) {
 	base := filepath.Base(manifestPath)
 	manifest, err := files.UnmarshalManifest(manifestPath)
 	if err != nil {
@@ -120,21 +215,85 @@ func ProcessJob(manifestPath string, mode string, outputPath string) {
 	environment := manifest.Environment
 	uuid := manifest.UUID
 	clerkUserId := manifest.UserID
+	defer metrics.JobStarted()()
+
+	// each stage is retried on its own according to the server policy and the
+	// manifest's overrides; every attempt is recorded on the manifest
+	policies := retry.Policies(manifest.Retry)
+	recordAttempt := func(attempt types.JobAttempt) {
+		manifest.Attempts = append(manifest.Attempts, attempt)
+		if err := utils.SetManifestField(manifestPath, "attempts", manifest.Attempts); err != nil {
+			log.Printf("Failed to record attempt for job %s: %v", uuid, err)
+		}
+	}
+
+	render := manifest.Render.Resolve(renderDefaults)
+	if err := render.Validate(); err != nil {
+		log.Printf("Invalid render settings for job %s: %v", uuid, err)
+		failJob(manifestPath, manifest, mode, err.Error())
+		return
+	}
+	manifest.Render = &render
+	if err := utils.SetManifestField(manifestPath, "render", manifest.Render); err != nil {
+		log.Printf("Failed to record render settings for job %s: %v", uuid, err)
+	}
+
+	// serve-mode jobs are paid for: hold the job's cost before rendering, commit
+	// it once the video is delivered and release it if the job fails
+	if mode == "serve" && clerkUserId != "" {
+		err := reserveTokens(ctx, manifestPath, manifest)
+		if ctx.Err() != nil {
+			interruptJob(manifestPath, manifest, "", "")
+			return
+		}
+		if err != nil {
+			log.Printf("Token reservation failed for job %s: %v", uuid, err)
+			failJob(manifestPath, manifest, mode, err.Error())
+			return
+		}
+	}
+
+	// still at 10 from the audio generation step
+	progress.Publish(progress.Event{JobUUID: uuid, Stage: progress.StageRecording, Percent: 10, Message: "Starting up video recording..."})
 
 	videoFolder := constants.VideoFolder()
 	if err := os.MkdirAll(videoFolder, 0755); err != nil {
 		log.Printf("Failed to create video folder: %v", err)
-		utils.AddErrorToManifest(manifestPath, err.Error())
+		failJob(manifestPath, manifest, mode, err.Error())
 		return
 	}
 	webmPath := filepath.Join(videoFolder, uuid+".webm")
 
-	// Call the Puppeteer script using node with the uuid and explicit output path.
-	puppeteerFailed := RunPuppeteerForUUID(uuid, mode, manifestPath, webmPath)
-	if puppeteerFailed {
-		log.Printf("Puppeteer recording failed for job %s", uuid)
-		utils.AddErrorToManifest(manifestPath, "Puppeteer recording failed")
-		return
+	if recordingSucceeded(manifest) && fileExists(webmPath) {
+		// a previous run of this job recorded the video but failed in a later stage
+		log.Printf("Reusing previously recorded webm for job %s", uuid)
+	} else {
+		// Call the Puppeteer script using node with the uuid and explicit output path,
+		// bounding its runtime by an estimate from the audio durations and pauses.
+		maxRuntime := MaxRecordingRuntime(manifest, constants.RenderTimeoutFactor())
+		log.Printf("Job %s may record for at most %s", uuid, maxRuntime.Round(time.Second))
+		err := runStage(ctx, retry.StageRecording, policies[retry.StageRecording], func(attempt int) error {
+			actionTimings, err := RunPuppeteerForUUID(ctx, uuid, manifestPath, webmPath, maxRuntime, render, endpoints)
+			if err != nil {
+				log.Printf("Puppeteer recording attempt %d failed for job %s: %v", attempt, uuid, err)
+				return err
+			}
+			if len(actionTimings) > 0 {
+				if err := utils.SetManifestField(manifestPath, "actionTimings", actionTimings); err != nil {
+					log.Printf("Failed to save action timings for job %s: %v", uuid, err)
+				}
+			}
+			return nil
+		}, recordAttempt)
+		if ctx.Err() != nil {
+			interruptJob(manifestPath, manifest, webmPath, "")
+			return
+		}
+		if err != nil {
+			log.Printf("Puppeteer recording failed for job %s: %v", uuid, err)
+			failJob(manifestPath, manifest, mode, fmt.Sprintf("Puppeteer recording failed: %v", err))
+			return
+		}
 	}
 
 	// Use the provided outputPath if it's not empty, otherwise use the default
@@ -145,7 +304,7 @@ func ProcessJob(manifestPath string, mode string, outputPath string) {
 		outputDir := filepath.Dir(mp4Path)
 		if err := os.MkdirAll(outputDir, 0755); err != nil {
 			log.Printf("Failed to create output directory: %v", err)
-			utils.AddErrorToManifest(manifestPath, err.Error())
+			failJob(manifestPath, manifest, mode, err.Error())
 			return
 		}
 	} else {
@@ -154,9 +313,19 @@ func ProcessJob(manifestPath string, mode string, outputPath string) {
 
 	// Puppeteer succeeded, now convert to mp4
 	log.Printf("Converting webm to mp4 for job %s", uuid)
-	if err := utils.ConvertToMp4(webmPath, mp4Path, mode); err != nil {
+	err = runStage(ctx, retry.StageEncoding, policies[retry.StageEncoding], func(attempt int) error {
+		return utils.ConvertToMp4(ctx, webmPath, mp4Path, uuid, render)
+	}, recordAttempt)
+	if ctx.Err() != nil {
+		if outputPath == "" {
+			os.Remove(mp4Path)
+		}
+		interruptJob(manifestPath, manifest, webmPath, "")
+		return
+	}
+	if err != nil {
 		log.Errorf("Failed to convert webm to mp4 for job %s: %v", uuid, err)
-		utils.AddErrorToManifest(manifestPath, fmt.Sprintf("Failed to convert video: %v", err))
+		failJob(manifestPath, manifest, mode, fmt.Sprintf("Failed to convert video: %v", err))
 		return
 	}
 
@@ -168,41 +337,67 @@ func ProcessJob(manifestPath string, mode string, outputPath string) {
 		mp4Bytes, err := os.ReadFile(mp4Path)
 		if err != nil {
 			log.Printf("Failed to read mp4 file for job %s: %v", uuid, err)
-			utils.AddErrorToManifest(manifestPath, err.Error())
+			failJob(manifestPath, manifest, mode, err.Error())
 			return
 		}
 
 		log.Printf("Uploading mp4 for job %s", uuid)
-		mp4Url, err := cloud.UploadFileToS3(context.Background(), mp4Bytes, "v3/video", uuid+".mp4")
+		progress.Publish(progress.Event{JobUUID: uuid, Stage: progress.StageUpload, Percent: 100, Message: "Uploading video..."})
+		var mp4Url string
+		err = runStage(ctx, retry.StageUpload, policies[retry.StageUpload], func(attempt int) error {
+			var err error
+			mp4Url, err = cloud.UploadFileToS3(ctx, mp4Bytes, "v3/video", uuid+".mp4")
+			return err
+		}, recordAttempt)
+		if ctx.Err() != nil {
+			interruptJob(manifestPath, manifest, webmPath, mp4Path)
+			return
+		}
 		if err != nil {
 			log.Printf("Failed to upload file for job %s: %v", uuid, err)
-			utils.AddErrorToManifest(manifestPath, err.Error())
+			failJob(manifestPath, manifest, mode, err.Error())
 			return
 		}
 		log.Printf("Uploaded mp4 for job %s to %s", uuid, mp4Url)
 
+		notification := mail.Notification{
+			Template:   mail.TemplateSuccess,
+			Locale:     manifest.Locale,
+			LessonName: manifest.Lesson.Name,
+			CourseName: manifest.CourseName,
+			VideoURL:   mp4Url,
+		}
+		notification.Duration, notification.ThumbnailURL = describeVideo(mp4Path, uuid)
+
 		// use the clerk userID to get the email address of the user
 		// be sure to initialize the clerk client with the correct API key according to whether the environment of the job is staging or prod
-		shouldReturn := updateClerkUserData(environment, clerkUserId, manifestPath, mp4Url, uuid, base)
-		if shouldReturn {
+		if err := notifyUser(ctx, environment, clerkUserId, notification, uuid, policies[retry.StageNotify], recordAttempt); ctx.Err() != nil {
+			interruptJob(manifestPath, manifest, webmPath, mp4Path)
 			return
+		} else if err != nil {
+			// the video is delivered either way, so the job still succeeds
+			log.Printf("Failed to notify user for job %s: %v", uuid, err)
+			manifest.NotifyError = err.Error()
+			if err := utils.SetManifestField(manifestPath, "notifyError", manifest.NotifyError); err != nil {
+				log.Printf("Failed to record the notification error for job %s: %v", uuid, err)
+			}
 		}
+
+		// the video is delivered, so the reserved tokens are spent
+		settleTokens(manifestPath, manifest, true)
 	}
 
 	// serve or cli mode, move the manifest to the success folder.
 	if err := files.MoveFile(manifestPath, filepath.Join(constants.SuccessFolder(), base)); err != nil {
 		log.Printf("Failed to move manifest to success folder: %v", err)
 	} else {
-		log.Printf("Job %s processed successfully", uuid)
 		ramUsage, err := utils.GetRAMUsage()
 		if err != nil {
 			log.Printf("Failed to get RAM usage: %v", err)
 		}
-		environment := os.Getenv("ENVIRONMENT")
-		if environment == "" {
-			environment = "unknown env"
-		}
-		slack.SendSlackMessage(fmt.Sprintf("%s: Job %s processed successfully (RAM usage is at %s)", strings.ToUpper(environment), uuid, ramUsage))
+		message := fmt.Sprintf("Job processed successfully (RAM usage is at %s)", ramUsage)
+		log.Printf("Job %s processed successfully", uuid)
+		progress.Publish(progress.Event{JobUUID: uuid, Stage: progress.StageDone, Percent: 100, Message: message})
 	}
 
 	if mode == "cli" {
@@ -220,18 +415,18 @@ func ProcessJob(manifestPath string, mode string, outputPath string) {
 				log.Printf("Failed to copy output file: %v", err)
 			}
 
-			fmt.Println()
-			fmt.Println("✅ CodeVideo successfully generated and saved to " + finalizedFileName)
-			fmt.Println()
+			renderer.Messagef("")
+			renderer.Messagef("✅ CodeVideo successfully generated and saved to %s", finalizedFileName)
+			renderer.Messagef("")
 		} else {
 			// Just print that the file was generated in the custom location
-			fmt.Println()
-			fmt.Println("✅ CodeVideo successfully generated and saved to " + outputPath)
-			fmt.Println()
+			renderer.Messagef("")
+			renderer.Messagef("✅ CodeVideo successfully generated and saved to %s", outputPath)
+			renderer.Messagef("")
 		}
 	}
 
-	// cleanup: remove the mp4 and webm files, decrement 10 tokens from the user
+	// cleanup: remove the mp4 and webm files
 
 	if outputPath == "" {
 		if err := os.Remove(mp4Path); err != nil {
@@ -244,83 +439,206 @@ func ProcessJob(manifestPath string, mode string, outputPath string) {
 	}
 }
 
-func updateClerkUserData(environment string, clerkUserId string, manifestPath string, mp4Url string, uuid string, base string) bool {
-	apiKey := os.Getenv("CLERK_SECRET_KEY")
-	if environment == "staging" {
-		apiKey = os.Getenv("CLERK_SECRET_KEY_STAGING")
+// describeVideo probes the rendered video's duration and uploads a thumbnail for
+// the notification email. Both are best-effort; failures only omit them from the email.
+func describeVideo(mp4Path string, uuid string) (time.Duration, string) {
+	duration, err := utils.ProbeDuration(mp4Path)
+	if err != nil {
+		log.Printf("Failed to probe duration for job %s: %v", uuid, err)
 	}
 
-	if apiKey == "" {
-		log.Printf("CLERK_SECRET_KEY not set")
+	thumbnailPath := filepath.Join(constants.VideoFolder(), uuid+".jpg")
+	defer os.Remove(thumbnailPath)
+	if err := utils.ExtractThumbnail(mp4Path, thumbnailPath, min(duration/2, 3*time.Second)); err != nil {
+		log.Printf("Failed to extract thumbnail for job %s: %v", uuid, err)
+		return duration, ""
 	}
-	config := &clerk.ClientConfig{}
-	config.Key = &apiKey
-	client := user.NewClient(config)
-	clerkUser, err := client.Get(context.Background(), clerkUserId)
+	thumbnailBytes, err := os.ReadFile(thumbnailPath)
 	if err != nil {
-		log.Printf("Failed to get user: %v", err)
-		utils.AddErrorToManifest(manifestPath, err.Error())
-		return true
+		log.Printf("Failed to read thumbnail for job %s: %v", uuid, err)
+		return duration, ""
+	}
+	thumbnailUrl, err := cloud.UploadFileToS3(context.Background(), thumbnailBytes, "v3/thumbnail", uuid+".jpg")
+	if err != nil {
+		log.Printf("Failed to upload thumbnail for job %s: %v", uuid, err)
+		return duration, ""
 	}
-	userEmail := clerkUser.EmailAddresses[0].EmailAddress
+	return duration, thumbnailUrl
+}
 
-	// Then send an email notification including the mp4 URL.
-	if err := mail.SendEmail(userEmail, mp4Url); err != nil {
-		log.Printf("Failed to send email for job %s: %v", uuid, err)
+// failJob records the failure on the manifest, moves it to the error folder
+// (the dead-letter queue) and, in serve mode, lets the user know by email that
+// their video could not be generated.
+func failJob(manifestPath string, manifest *types.CodeVideoManifest, mode string, reason string) {
+	utils.AddErrorToManifest(manifestPath, reason)
+	settleTokens(manifestPath, manifest, false)
+	progress.Publish(progress.Event{JobUUID: manifest.UUID, Stage: progress.StageFailed, Message: reason})
+	defer func() {
+		if err := files.MoveFile(manifestPath, filepath.Join(constants.ErrorFolder(), filepath.Base(manifestPath))); err != nil {
+			log.Printf("Failed to move manifest to error folder: %v", err)
+		}
+	}()
+	if mode != "serve" {
+		return
+	}
 
-		// add an error key and value to the manifest file.
-		utils.AddErrorToManifest(manifestPath, err.Error())
-		log.Printf("Failed to add error to manifest file: %v", err)
+	userEmail, err := directory.Email(context.Background(), manifest.Environment, manifest.UserID)
+	if err != nil {
+		log.Printf("Skipping failure notification for job %s: %v", manifest.UUID, err)
+		return
+	}
+	notification := mail.Notification{
+		Template:     mail.TemplateFailure,
+		Locale:       manifest.Locale,
+		LessonName:   manifest.Lesson.Name,
+		CourseName:   manifest.CourseName,
+		ErrorSummary: reason,
+	}
+	if err := mail.SendEmail(userEmail, notification); err != nil {
+		log.Printf("Failed to send failure email for job %s: %v", manifest.UUID, err)
+	}
+}
 
-		if err := files.MoveFile(manifestPath, filepath.Join(constants.ErrorFolder(), base)); err != nil {
-			log.Printf("Failed to move manifest to error folder: %v", err)
+// interruptJob leaves a job cancelled by a shutdown in the new folder, marked
+// as interrupted so the next start requeues it. A partial recording is
+// removed; a finished one is kept for reuse, as are the given leftover files.
+func interruptJob(manifestPath string, manifest *types.CodeVideoManifest, webmPath string, mp4Path string) {
+	log.Printf("Job %s was interrupted by shutdown; it will be requeued on the next start", manifest.UUID)
+	if webmPath != "" && !recordingSucceeded(manifest) {
+		os.Remove(webmPath)
+	}
+	if mp4Path != "" {
+		os.Remove(mp4Path)
+	}
+	markInterrupted(manifestPath)
+	progress.Publish(progress.Event{JobUUID: manifest.UUID, Stage: progress.StageInterrupted, Message: "Interrupted by shutdown"})
+}
+
+// runStage runs a pipeline stage with retries and records its duration and
+// outcome in the metrics.
+func runStage(ctx context.Context, stage string, policy types.RetryPolicy, fn func(attempt int) error, record func(types.JobAttempt)) error {
+	started := time.Now()
+	err := retry.Do(ctx, stage, policy, fn, record)
+	metrics.ObserveStage(stage, time.Since(started), err)
+	return err
+}
+
+// recordingSucceeded reports whether a previous run of the job finished the recording stage.
+func recordingSucceeded(manifest *types.CodeVideoManifest) bool {
+	for _, attempt := range manifest.Attempts {
+		if attempt.Stage == retry.StageRecording && attempt.Error == "" {
+			return true
 		}
-		return true
 	}
+	return false
+}
 
-	log.Printf("Email sent to %s for job %s", userEmail, uuid)
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
 
-	// also update the user metadata to decrement the number of tokens by TOKEN_DECREMENT_AMOUNT
-	currentTokens := 0
-
-	if clerkUser.PublicMetadata != nil {
-		var meta map[string]interface{}
-		if err := json.Unmarshal(clerkUser.PublicMetadata, &meta); err == nil {
-			switch v := meta["tokens"].(type) {
-			case float64:
-				currentTokens = int(v)
-			case string:
-				currentTokens, _ = strconv.Atoi(v)
-			}
+// notifyUser emails the user about their video (the retried notify stage).
+// A user without an email address is not an error: the video is delivered
+// regardless.
+func notifyUser(ctx context.Context, environment string, userID string, notification mail.Notification, uuid string, policy types.RetryPolicy, recordAttempt func(types.JobAttempt)) error {
+	var userEmail string
+	err := runStage(ctx, retry.StageNotify, policy, func(attempt int) error {
+		var err error
+		userEmail, err = directory.Email(ctx, environment, userID)
+		if errors.Is(err, accounts.ErrNoEmail) {
+			// retrying won't give the user an address
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get user: %w", err)
 		}
+
+		// Then send an email notification including the mp4 URL.
+		if err := mail.SendEmail(userEmail, notification); err != nil {
+			return fmt.Errorf("failed to send email for job %s: %w", uuid, err)
+		}
+		return nil
+	}, recordAttempt)
+	if err != nil {
+		return err
 	}
 
-	newTokens := currentTokens - constants.TOKEN_DECREMENT_AMOUNT
-	metadata, _ := json.Marshal(map[string]interface{}{"tokens": newTokens})
-	params := user.UpdateMetadataParams{
-		PublicMetadata: (*json.RawMessage)(&metadata),
+	if userEmail == "" {
+		log.Printf("User %s has no email address; skipping notification for job %s", userID, uuid)
+		return nil
 	}
+	log.Printf("Email sent to %s for job %s", userEmail, uuid)
+	return nil
+}
 
-	if _, err := client.UpdateMetadata(context.Background(), clerkUserId, &params); err != nil {
-		log.Printf("Failed to update user metadata: %v", err)
-		utils.AddErrorToManifest(manifestPath, err.Error())
+// reserveTokens holds the job's cost, estimated from its length, on the
+// user's balance. A reservation kept by an interrupted run is reused.
+func reserveTokens(ctx context.Context, manifestPath string, manifest *types.CodeVideoManifest) error {
+	if manifest.Billing != nil && manifest.Billing.State == billing.StateReserved {
+		log.Printf("Reusing the %d tokens reserved for job %s", manifest.Billing.Tokens, manifest.UUID)
+		return nil
+	}
+	cost := billing.Cost(EstimateRecordingDuration(manifest))
+	reserved, err := biller.Reserve(ctx, manifest.Environment, manifest.UserID, cost)
+	if err != nil {
+		return err
+	}
+	manifest.Billing = &types.JobBilling{Tokens: reserved, State: billing.StateReserved}
+	if err := utils.SetManifestField(manifestPath, "billing", manifest.Billing); err != nil {
+		// without the record the reservation could never be settled
+		biller.Release(ctx, manifest.Environment, manifest.UserID, reserved)
+		return fmt.Errorf("failed to record token reservation: %w", err)
+	}
+	log.Printf("Reserved %d tokens for job %s", reserved, manifest.UUID)
+	return nil
+}
+
+// settleTokens commits (spend) or releases the job's reservation, if it holds one.
+func settleTokens(manifestPath string, manifest *types.CodeVideoManifest, spend bool) {
+	if manifest.Billing == nil || manifest.Billing.State != billing.StateReserved {
+		return
+	}
+	ctx := context.Background()
+	var err error
+	state := billing.StateReleased
+	if spend {
+		state = billing.StateCommitted
+		err = biller.Commit(ctx, manifest.Environment, manifest.UserID, manifest.UUID, manifest.Billing.Tokens)
 	} else {
-		log.Printf("Successfully decremented user tokens from %d to %d", currentTokens, newTokens)
+		err = biller.Release(ctx, manifest.Environment, manifest.UserID, manifest.Billing.Tokens)
 	}
-	return false
+	if err != nil {
+		log.Printf("Failed to settle the %d tokens reserved for job %s (%s): %v", manifest.Billing.Tokens, manifest.UUID, state, err)
+		utils.AddErrorToManifest(manifestPath, err.Error())
+		return
+	}
+	manifest.Billing.State = state
+	if err := utils.SetManifestField(manifestPath, "billing", manifest.Billing); err != nil {
+		log.Printf("Failed to record token settlement for job %s: %v", manifest.UUID, err)
+	}
+	log.Printf("Tokens for job %s %s: %d", manifest.UUID, state, manifest.Billing.Tokens)
 }
 
-func RunPuppeteerForUUID(uuid string, mode string, manifestPath string, webmOutputPath string) bool {
-	// Access the global configuration
-	resolution := config.GlobalConfig.Resolution
-	orientation := config.GlobalConfig.Orientation
+// RunPuppeteerForUUID records the job's video with the Puppeteer runner at the
+// size and frame rate of the render settings and returns the per-action timings reported
+// by the runner. The runner is stopped if it reports no progress within
+// constants.StallTimeout() or runs longer than maxRuntime.
+func RunPuppeteerForUUID(ctx context.Context, uuid string, manifestPath string, webmOutputPath string, maxRuntime time.Duration, render types.RenderSettings, endpoints staticserver.Endpoints) ([]types.ActionTiming, error) {
+	width, height, err := render.Dimensions()
+	if err != nil {
+		return nil, err
+	}
+	frameRate := render.FrameRate
+	if frameRate == 0 {
+		frameRate = types.DefaultFrameRate
+	}
 
 	nodeScriptPath := constants.PuppeteerRunnerPath()
 
 	// Check if the script exists
 	if _, err := os.Stat(nodeScriptPath); err != nil {
-		log.Printf("Node script is unavailable at %s: %v", nodeScriptPath, err)
-		return true
+		return nil, fmt.Errorf("node script is unavailable at %s: %w", nodeScriptPath, err)
 	}
 
 	log.Printf("Using node script at: %s", nodeScriptPath)
@@ -328,34 +646,47 @@ func RunPuppeteerForUUID(uuid string, mode string, manifestPath string, webmOutp
 	cmd := exec.Command("node", nodeScriptPath,
 		"--uuid", uuid,
 		"--os", os.Getenv("OPERATING_SYSTEM"),
-		"--resolution", resolution,
-		"--orientation", orientation,
+		"--width", strconv.Itoa(width),
+		"--height", strconv.Itoa(height),
+		"--frame-rate", strconv.Itoa(frameRate),
 		"--manifest-path", manifestPath,
-		"--output-webm", webmOutputPath)
+		"--output-webm", webmOutputPath,
+		"--static-url", endpoints.StaticURL)
+	if endpoints.ManifestURL != "" {
+		cmd.Args = append(cmd.Args, "--manifest-url", endpoints.ManifestURL)
+	}
 
 	// Add debug flag if enabled
-	if config.GlobalConfig.Debug {
+	if render.Debug {
 		cmd.Args = append(cmd.Args, "--debug")
 	}
 
 	stdoutPipe, err := cmd.StdoutPipe()
 	if err != nil {
-		log.Printf("Error obtaining stdout pipe: %v", err)
-		return true
+		return nil, fmt.Errorf("error obtaining stdout pipe: %w", err)
 	}
 	stderrPipe, err := cmd.StderrPipe()
 	if err != nil {
-		log.Printf("Error obtaining stderr pipe: %v", err)
-		return true
+		return nil, fmt.Errorf("error obtaining stderr pipe: %w", err)
 	}
 
 	if err := cmd.Start(); err != nil {
-		log.Printf("Job %s failed to start: %v", uuid, err)
-		return true
+		return nil, fmt.Errorf("failed to start: %w", err)
 	}
 
-	// Stream stdout concurrently.
+	timer := newActionTimer()
+	dog := newWatchdog(constants.StallTimeout(), maxRuntime)
+	watchdogDone := make(chan struct{})
+	defer close(watchdogDone)
+	go dog.watch(ctx, uuid, cmd, watchdogDone)
+
+	var fatalMessage string
+	var streams sync.WaitGroup
+	streams.Add(2)
+
+	// Stream stdout concurrently, handling structured runner events.
 	go func() {
+		defer streams.Done()
 		scanner := bufio.NewScanner(stdoutPipe)
 		// Allow very long lines: a manifest dump with data-URI audio (or Chrome
 		// dumpio) can exceed bufio's 64KB default. Hitting that limit kills the
@@ -363,22 +694,37 @@ func RunPuppeteerForUUID(uuid string, mode string, manifestPath string, webmOutp
 		scanner.Buffer(make([]byte, 1024*1024), 64*1024*1024)
 		for scanner.Scan() {
 			text := scanner.Text()
-			log.Printf("[Puppeteer stdout]: %s", text)
-			if strings.Contains(text, "progress") {
-				// regex everything between ' ' characters
-				progress, err := utils.ExtractProgress(text)
-				if err != nil {
-					log.Printf("Failed to extract progress: %v", err)
-				}
-				log.Printf("Extracted progress: %f", progress)
-
+			event, isEvent, err := ParseRunnerEvent(text)
+			if err != nil {
+				log.Printf("Job %s: %v", uuid, err)
+				continue
+			}
+			if !isEvent {
+				log.Printf("[Puppeteer stdout]: %s", text)
+				continue
+			}
+			timer.handle(event)
+			dog.observe(event)
+			switch event.Type {
+			case RunnerEventProgress:
 				// now, since we always are already at 10, we want to show progress even if we are less than 0,
 				// so we scale it in from 10 to 90
 				// (we also have conversion to mp4 still to do)
-				progress = 10 + (progress * 0.8)
-				if mode == "cli" {
-					renderer.RenderProgressToConsole(progress, "Rendering video...")
-				}
+				progress.Publish(progress.Event{
+					JobUUID:      uuid,
+					Stage:        progress.StageRecording,
+					Percent:      10 + (event.Progress * 0.8),
+					ActionIndex:  event.CurrentAction,
+					TotalActions: event.TotalActions,
+					Message:      "Rendering video...",
+				})
+			case RunnerEventWarning:
+				log.Warnf("Job %s runner warning: %s", uuid, event.Message)
+			case RunnerEventFatal:
+				log.Errorf("Job %s runner fatal error: %s", uuid, event.Message)
+				fatalMessage = event.Message
+			default:
+				log.Debugf("Job %s runner event: %s", uuid, text)
 			}
 		}
 		if err := scanner.Err(); err != nil {
@@ -388,6 +734,7 @@ func RunPuppeteerForUUID(uuid string, mode string, manifestPath string, webmOutp
 
 	// Stream stderr concurrently.
 	go func() {
+		defer streams.Done()
 		scanner := bufio.NewScanner(stderrPipe)
 		scanner.Buffer(make([]byte, 1024*1024), 64*1024*1024)
 		for scanner.Scan() {
@@ -398,10 +745,19 @@ func RunPuppeteerForUUID(uuid string, mode string, manifestPath string, webmOutp
 		}
 	}()
 
-	// Wait for the command to finish.
+	// Drain both streams before waiting, as required by StdoutPipe/StderrPipe.
+	streams.Wait()
 	if err := cmd.Wait(); err != nil {
-		log.Printf("Job %s failed: %v", uuid, err)
-		return true
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("recording interrupted: %w", ctx.Err())
+		}
+		if watchdogErr := dog.Err(); watchdogErr != nil {
+			return nil, watchdogErr
+		}
+		if fatalMessage != "" {
+			return nil, fmt.Errorf("%s (%w)", fatalMessage, err)
+		}
+		return nil, err
 	}
-	return false
+	return timer.Timings(), nil
 }
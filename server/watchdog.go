@@ -0,0 +1,184 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/codevideo/codevideo-cli/types"
+)
+
+const (
+	// fallbacks for when the IDE props don't set pauses (matching data/config.json)
+	defaultKeyboardTypingPauseMs = 100
+	defaultStandardPauseMs       = 1000
+
+	// browser launch, page load and teardown on top of the actions themselves
+	recordingStartupAllowance = 2 * time.Minute
+
+	// speaking rate used when an audio item has no measured duration (~150 wpm)
+	wordsPerSecond = 2.5
+
+	// how long the runner gets to close Chrome after SIGTERM before it is killed
+	runnerStopGracePeriod = 10 * time.Second
+)
+
+// EstimateRecordingDuration estimates how long playing the manifest's actions
+// takes, from the audio durations and the IDE pause settings.
+func EstimateRecordingDuration(manifest *types.CodeVideoManifest) time.Duration {
+	typingPause := time.Duration(defaultKeyboardTypingPauseMs) * time.Millisecond
+	standardPause := time.Duration(defaultStandardPauseMs) * time.Millisecond
+	if props := manifest.CodeVideoIDEProps; props != nil {
+		if props.KeyboardTypingPauseMs != nil {
+			typingPause = time.Duration(*props.KeyboardTypingPauseMs) * time.Millisecond
+		}
+		if props.StandardPauseMs != nil {
+			standardPause = time.Duration(*props.StandardPauseMs) * time.Millisecond
+		}
+	}
+
+	audioDurations := make(map[string]time.Duration, len(manifest.AudioItems))
+	for _, item := range manifest.AudioItems {
+		if item.DurationMs > 0 {
+			audioDurations[item.Text] = time.Duration(item.DurationMs) * time.Millisecond
+		}
+	}
+
+	actions := manifest.Actions
+	if len(actions) == 0 {
+		actions = manifest.Lesson.Actions
+	}
+
+	var total time.Duration
+	for _, action := range actions {
+		total += standardPause
+		switch {
+		case strings.HasPrefix(action.Name, "author-speak"):
+			duration, ok := audioDurations[action.Value]
+			if !ok {
+				words := len(strings.Fields(action.Value))
+				duration = time.Duration(float64(words) / wordsPerSecond * float64(time.Second))
+			}
+			total += duration
+		case strings.HasSuffix(action.Name, "-type"):
+			total += time.Duration(len(action.Value)) * typingPause
+		}
+	}
+	return total
+}
+
+// MaxRecordingRuntime is the runtime after which a recording is considered hung
+// even if it still reports progress.
+func MaxRecordingRuntime(manifest *types.CodeVideoManifest, factor float64) time.Duration {
+	return recordingStartupAllowance + time.Duration(float64(EstimateRecordingDuration(manifest))*factor)
+}
+
+// watchdog kills the Puppeteer runner when it stops reporting progress or
+// runs past its maximum runtime.
+type watchdog struct {
+	stallTimeout time.Duration
+	maxRuntime   time.Duration
+
+	mu           sync.Mutex
+	started      time.Time
+	lastProgress time.Time
+	actionIndex  int
+	totalActions int
+	err          error
+}
+
+func newWatchdog(stallTimeout time.Duration, maxRuntime time.Duration) *watchdog {
+	now := time.Now()
+	return &watchdog{
+		stallTimeout: stallTimeout,
+		maxRuntime:   maxRuntime,
+		started:      now,
+		lastProgress: now,
+	}
+}
+
+// observe records progress and action events as signs of life. Warnings
+// don't count: a page stuck in an error loop must still be stopped.
+func (w *watchdog) observe(event RunnerEvent) {
+	if event.Type == RunnerEventWarning || event.Type == RunnerEventFatal {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.lastProgress = time.Now()
+	switch event.Type {
+	case RunnerEventProgress:
+		w.actionIndex = event.CurrentAction
+		w.totalActions = event.TotalActions
+	case RunnerEventActionStart:
+		w.actionIndex = event.Index
+	}
+}
+
+// check returns an error once the runner has stalled or exceeded its runtime.
+func (w *watchdog) check(now time.Time) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err != nil {
+		return w.err
+	}
+	if idle := now.Sub(w.lastProgress); idle > w.stallTimeout {
+		w.err = fmt.Errorf("recording stalled at action %d of %d: no progress for %s", w.actionIndex, w.totalActions, idle.Round(time.Second))
+	} else if w.maxRuntime > 0 && now.Sub(w.started) > w.maxRuntime {
+		w.err = fmt.Errorf("recording exceeded its maximum runtime of %s at action %d of %d", w.maxRuntime.Round(time.Second), w.actionIndex, w.totalActions)
+	}
+	return w.err
+}
+
+// Err returns the reason the watchdog stopped the runner, if it did.
+func (w *watchdog) Err() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+// watch polls until done is closed, stopping the runner on the first failure
+// or when ctx is cancelled.
+func (w *watchdog) watch(ctx context.Context, uuid string, cmd *exec.Cmd, done <-chan struct{}) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ctx.Done():
+			log.Printf("Job %s cancelled; stopping the Puppeteer runner", uuid)
+			stopProcess(cmd, runnerStopGracePeriod)
+			return
+		case now := <-ticker.C:
+			if err := w.check(now); err != nil {
+				log.Errorf("Job %s: %v; stopping the Puppeteer runner", uuid, err)
+				stopProcess(cmd, runnerStopGracePeriod)
+				return
+			}
+		}
+	}
+}
+
+// stopProcess asks the process to terminate, which lets Puppeteer close
+// Chrome, and kills it if it is still running after the grace period. Where
+// SIGTERM isn't supported (Windows) the process is killed right away.
+func stopProcess(cmd *exec.Cmd, grace time.Duration) {
+	if cmd.Process == nil {
+		return
+	}
+	if err := cmd.Process.Signal(syscall.SIGTERM); err != nil {
+		cmd.Process.Kill()
+		return
+	}
+	time.AfterFunc(grace, func() {
+		// returns an error if the process already exited, which is fine
+		cmd.Process.Kill()
+	})
+}
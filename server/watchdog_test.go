@@ -0,0 +1,66 @@
+package server
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codevideo/codevideo-cli/types"
+)
+
+func TestEstimateRecordingDuration(t *testing.T) {
+	typingPause := 50
+	manifest := &types.CodeVideoManifest{
+		Actions: []types.Action{
+			{Name: "author-speak-before", Value: "Hello there"},
+			{Name: "author-speak-before", Value: "one two three four five"},
+			{Name: "editor-type", Value: "0123456789"},
+			{Name: "editor-enter", Value: "1"},
+		},
+		AudioItems: []types.AudioItem{
+			{Text: "Hello there", DurationMs: 4000},
+		},
+		CodeVideoIDEProps: &types.CodeVideoIDEProps{KeyboardTypingPauseMs: &typingPause},
+	}
+	// 4 standard pauses + measured audio + 5 words at 2.5 words/s + 10 typed characters
+	want := 4*time.Second + 4*time.Second + 2*time.Second + 500*time.Millisecond
+	if got := EstimateRecordingDuration(manifest); got != want {
+		t.Errorf("EstimateRecordingDuration() = %s, want %s", got, want)
+	}
+}
+
+func TestWatchdogReportsStallAtAction(t *testing.T) {
+	dog := newWatchdog(time.Minute, time.Hour)
+	dog.observe(RunnerEvent{Type: RunnerEventProgress, CurrentAction: 7, TotalActions: 12})
+	// warnings are not progress
+	dog.observe(RunnerEvent{Type: RunnerEventWarning, Message: "Page error"})
+
+	if err := dog.check(time.Now().Add(30 * time.Second)); err != nil {
+		t.Fatalf("unexpected error before the stall timeout: %v", err)
+	}
+	err := dog.check(time.Now().Add(2 * time.Minute))
+	if err == nil || !strings.Contains(err.Error(), "stalled at action 7 of 12") {
+		t.Fatalf("expected stall error, got %v", err)
+	}
+	if dog.Err() != err {
+		t.Error("Err() should return the recorded failure")
+	}
+}
+
+func TestWatchdogEnforcesMaxRuntime(t *testing.T) {
+	dog := newWatchdog(time.Minute, 5*time.Minute)
+	later := time.Now()
+	for i := 0; i < 10; i++ {
+		later = later.Add(40 * time.Second)
+		dog.mu.Lock()
+		dog.lastProgress = later
+		dog.mu.Unlock()
+		if err := dog.check(later); err != nil {
+			if !strings.Contains(err.Error(), "maximum runtime") || i < 7 {
+				t.Fatalf("unexpected error at step %d: %v", i, err)
+			}
+			return
+		}
+	}
+	t.Fatal("expected the maximum runtime to be exceeded")
+}
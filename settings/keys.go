@@ -0,0 +1,84 @@
+package settings
+
+// Kind is the type a setting's value must parse as.
+type Kind string
+
+const (
+	KindString   Kind = "string"
+	KindInt      Kind = "int"
+	KindBool     Kind = "bool"
+	KindFloat    Kind = "float"
+	KindDuration Kind = "duration"
+)
+
+// Key is a setting: its name in the config file ("section.name"), the
+// environment variable the rest of CodeVideo reads it from, and the command
+// line flag that overrides it, if any.
+type Key struct {
+	Name        string
+	Env         string
+	Flag        string
+	Kind        Kind
+	Default     string
+	Secret      bool
+	Description string
+}
+
+// Sections in the order they are shown.
+var Sections = []string{"render", "tts", "storage", "notify", "server"}
+
+// Keys lists every setting that can be given in a config file.
+var Keys = []Key{
+	{Name: "render.resolution", Env: "CODEVIDEO_RESOLUTION", Flag: "resolution", Kind: KindString, Default: "1080p", Description: "720p, 1080p, 1440p, 4K, 1:1, 4:5 or WIDTHxHEIGHT"},
+	{Name: "render.orientation", Env: "CODEVIDEO_ORIENTATION", Flag: "orientation", Kind: KindString, Default: "landscape", Description: "landscape or portrait"},
+	{Name: "render.frame_rate", Env: "CODEVIDEO_FRAME_RATE", Flag: "frame-rate", Kind: KindInt, Default: "60", Description: "Frames per second of the recording and the mp4"},
+	{Name: "render.encoding_profile", Env: "CODEVIDEO_ENCODING_PROFILE", Flag: "encoding-profile", Kind: KindString, Default: "standard", Description: "standard, high or draft"},
+	{Name: "render.stall_timeout", Env: "CODEVIDEO_STALL_TIMEOUT", Kind: KindDuration, Default: "3m", Description: "Kill a recording after this long without progress"},
+	{Name: "render.timeout_factor", Env: "CODEVIDEO_RENDER_TIMEOUT_FACTOR", Kind: KindFloat, Default: "3", Description: "Kill a recording after this multiple of its estimated length"},
+	{Name: "render.retry_policy", Env: "CODEVIDEO_RETRY_POLICY", Kind: KindString, Description: "Per-stage retry overrides (JSON)"},
+	{Name: "render.ffmpeg_path", Env: "CODEVIDEO_FFMPEG_PATH", Kind: KindString, Description: "ffmpeg executable (default: bundled, then PATH)"},
+	{Name: "render.puppeteer_runner_path", Env: "CODEVIDEO_PUPPETEER_RUNNER_PATH", Kind: KindString, Description: "Puppeteer runner script"},
+
+	{Name: "tts.provider", Env: "CODEVIDEO_TTS_PROVIDER", Kind: KindString, Default: "elevenlabs", Description: "elevenlabs or kokoro"},
+	{Name: "tts.service_url", Env: "TTS_SERVICE_URL", Kind: KindString, Default: "http://localhost:3000", Description: "Self-hosted codevideo-tts service"},
+	{Name: "tts.api_key", Env: "TTS_API_KEY", Kind: KindString, Secret: true, Description: "Bearer token for the TTS service"},
+	{Name: "tts.elevenlabs_api_key", Env: "ELEVEN_LABS_API_KEY", Kind: KindString, Secret: true, Description: "ElevenLabs API key"},
+	{Name: "tts.elevenlabs_voice_id", Env: "ELEVEN_LABS_VOICE_ID", Kind: KindString, Description: "ElevenLabs voice"},
+	{Name: "tts.elevenlabs_voice_id_chris", Env: "ELEVEN_LABS_VOICE_ID_CHRIS", Kind: KindString, Description: "ElevenLabs voice used when tts.elevenlabs_voice_id is unset"},
+
+	{Name: "storage.work_dir", Env: "CODEVIDEO_WORK_DIR", Kind: KindString, Description: "Work folder with the new, error, success and video folders"},
+	{Name: "storage.output_dir", Env: "CODEVIDEO_OUTPUT_DIR", Kind: KindString, Description: "Where CLI videos are saved"},
+	{Name: "storage.log_dir", Env: "CODEVIDEO_LOG_DIR", Kind: KindString, Description: "Log folder"},
+	{Name: "storage.ledger_file", Env: "CODEVIDEO_LEDGER_FILE", Kind: KindString, Description: "Token usage ledger"},
+	{Name: "storage.s3_key_id", Env: "CODEVIDEO_S3_KEY_ID", Kind: KindString, Secret: true, Description: "S3 access key ID"},
+	{Name: "storage.s3_secret", Env: "CODEVIDEO_S3_SECRET", Kind: KindString, Secret: true, Description: "S3 secret access key"},
+
+	{Name: "notify.slack_webhook_url", Env: "SLACK_WEBHOOK_URL", Kind: KindString, Secret: true, Description: "Slack webhook for job notifications"},
+	{Name: "notify.slack_progress", Env: "CODEVIDEO_SLACK_PROGRESS", Flag: "slack-progress", Kind: KindBool, Description: "Post progress milestones to Slack (default: on in serve mode)"},
+	{Name: "notify.mailjet_public_key", Env: "MJ_APIKEY_PUBLIC", Kind: KindString, Secret: true, Description: "Mailjet public API key"},
+	{Name: "notify.mailjet_private_key", Env: "MJ_APIKEY_PRIVATE", Kind: KindString, Secret: true, Description: "Mailjet private API key"},
+	{Name: "notify.mail_template_dir", Env: "CODEVIDEO_MAIL_TEMPLATE_DIR", Kind: KindString, Description: "Folder overriding the email templates"},
+
+	{Name: "server.environment", Env: "ENVIRONMENT", Kind: KindString, Description: "Name of this deployment in notifications"},
+	{Name: "server.max_concurrent_jobs", Env: "CODEVIDEO_MAX_CONCURRENT_JOBS", Kind: KindInt, Default: "2", Description: "Jobs rendering at once"},
+	{Name: "server.max_jobs_per_user", Env: "CODEVIDEO_MAX_JOBS_PER_USER", Kind: KindInt, Default: "1", Description: "Jobs of one user rendering at once (0 for no cap)"},
+	{Name: "server.drain_timeout", Env: "CODEVIDEO_DRAIN_TIMEOUT", Kind: KindDuration, Default: "5m", Description: "Time in-flight jobs get to finish on shutdown"},
+	{Name: "server.api_addr", Env: "CODEVIDEO_API_ADDR", Kind: KindString, Default: "127.0.0.1:8080", Description: "Job status API address (off when empty)"},
+	{Name: "server.metrics_addr", Env: "CODEVIDEO_METRICS_ADDR", Flag: "metrics-addr", Kind: KindString, Description: "Prometheus metrics address (off when empty)"},
+	{Name: "server.static_port", Env: "CODEVIDEO_STATIC_PORT", Kind: KindInt, Description: "Static server port (default: a free port)"},
+	{Name: "server.manifest_port", Env: "CODEVIDEO_MANIFEST_PORT", Kind: KindInt, Description: "Manifest server port (default: a free port)"},
+	{Name: "server.user_directory", Env: "CODEVIDEO_USER_DIRECTORY", Kind: KindString, Default: "clerk", Description: "clerk, file or none"},
+	{Name: "server.users_file", Env: "CODEVIDEO_USERS_FILE", Kind: KindString, Description: "Users file of the file directory"},
+	{Name: "server.clerk_secret_key", Env: "CLERK_SECRET_KEY", Kind: KindString, Secret: true, Description: "Clerk secret key (production)"},
+	{Name: "server.clerk_secret_key_staging", Env: "CLERK_SECRET_KEY_STAGING", Kind: KindString, Secret: true, Description: "Clerk secret key (staging)"},
+}
+
+// Lookup returns the key with the given name.
+func Lookup(name string) (Key, bool) {
+	for _, key := range Keys {
+		if key.Name == name {
+			return key, true
+		}
+	}
+	return Key{}, false
+}
@@ -0,0 +1,216 @@
+package settings
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// Source is the layer a value came from, highest precedence first.
+type Source string
+
+const (
+	SourceFlag    Source = "flag"
+	SourceEnv     Source = "env"
+	SourceProject Source = "project file"
+	SourceUser    Source = "user file"
+	SourceDefault Source = "default"
+)
+
+// fileNames are the config file names looked up, in order.
+var fileNames = []string{"codevideo.yaml", "codevideo.yml", "codevideo.toml"}
+
+// applied holds the environment variables set by Apply, so a later Load still
+// attributes them to their file.
+var applied = map[string]string{}
+
+// Value is the effective value of a key and where it came from. Origin names
+// the flag, environment variable or file.
+type Value struct {
+	Key
+	Value  string
+	Source Source
+	Origin string
+}
+
+// Config is the resolved configuration: flags > env > project file > user
+// file > defaults.
+type Config struct {
+	ProjectFile string
+	UserFile    string
+	Values      []Value
+}
+
+// Load resolves every key. The project file is CODEVIDEO_CONFIG, or
+// codevideo.yaml/.yml/.toml in the working directory; the user file is
+// codevideo.yaml/.yml/.toml in the user config directory (e.g.
+// ~/.config/codevideo). flags may be nil; only flags that were set count.
+func Load(flags *pflag.FlagSet) (*Config, error) {
+	config := &Config{}
+
+	var project, user map[string]string
+	var err error
+	if path := os.Getenv("CODEVIDEO_CONFIG"); path != "" {
+		config.ProjectFile = path
+	} else {
+		config.ProjectFile = findFile(".")
+	}
+	if config.ProjectFile != "" {
+		if project, err = ReadFile(config.ProjectFile); err != nil {
+			return nil, err
+		}
+	}
+	if dir, err := os.UserConfigDir(); err == nil {
+		config.UserFile = findFile(filepath.Join(dir, "codevideo"))
+	}
+	if config.UserFile != "" {
+		if user, err = ReadFile(config.UserFile); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, key := range Keys {
+		value := Value{Key: key, Value: key.Default, Source: SourceDefault}
+		if v, ok := user[key.Name]; ok {
+			value.Value, value.Source, value.Origin = v, SourceUser, config.UserFile
+		}
+		if v, ok := project[key.Name]; ok {
+			value.Value, value.Source, value.Origin = v, SourceProject, config.ProjectFile
+		}
+		if v := os.Getenv(key.Env); v != "" && applied[key.Env] != v {
+			value.Value, value.Source, value.Origin = v, SourceEnv, key.Env
+		}
+		if flags != nil && key.Flag != "" {
+			if flag := flags.Lookup(key.Flag); flag != nil && flag.Changed {
+				value.Value, value.Source, value.Origin = flag.Value.String(), SourceFlag, "--"+key.Flag
+			}
+		}
+		if err := validate(key, value.Value); err != nil {
+			return nil, fmt.Errorf("%s (from %s): %w", key.Name, value.Describe(), err)
+		}
+		config.Values = append(config.Values, value)
+	}
+	return config, nil
+}
+
+// Get returns the effective value of a key.
+func (c *Config) Get(name string) string {
+	for _, value := range c.Values {
+		if value.Name == name {
+			return value.Value
+		}
+	}
+	return ""
+}
+
+// Apply exports the values that came from config files to their environment
+// variables, where the rest of CodeVideo reads them. Variables that are
+// already set win, so the precedence is unchanged.
+func (c *Config) Apply() {
+	for _, value := range c.Values {
+		if value.Source == SourceProject || value.Source == SourceUser {
+			os.Setenv(value.Env, value.Value)
+			applied[value.Env] = value.Value
+		}
+	}
+}
+
+func findFile(dir string) string {
+	for _, name := range fileNames {
+		path := filepath.Join(dir, name)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
+
+// ReadFile reads a YAML or TOML config file (by extension) into key names and
+// values. Unknown sections and keys are errors.
+func ReadFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	sections := map[string]map[string]interface{}{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml":
+		err = toml.Unmarshal(data, &sections)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &sections)
+	default:
+		return nil, fmt.Errorf("config file %s must be .yaml, .yml or .toml", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	values := map[string]string{}
+	var problems []string
+	for section, entries := range sections {
+		for name, raw := range entries {
+			key, ok := Lookup(section + "." + name)
+			if !ok {
+				problems = append(problems, fmt.Sprintf("unknown setting %s.%s", section, name))
+				continue
+			}
+			value := fmt.Sprint(raw)
+			if err := validate(key, value); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %v", key.Name, err))
+				continue
+			}
+			values[key.Name] = value
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return nil, fmt.Errorf("config file %s: %s (see `codevideo config show --resolved` for the known settings)", path, strings.Join(problems, "; "))
+	}
+	return values, nil
+}
+
+func validate(key Key, value string) error {
+	if value == "" {
+		return nil
+	}
+	var err error
+	switch key.Kind {
+	case KindInt:
+		_, err = strconv.Atoi(value)
+	case KindBool:
+		_, err = strconv.ParseBool(value)
+	case KindFloat:
+		_, err = strconv.ParseFloat(value, 64)
+	case KindDuration:
+		_, err = time.ParseDuration(value)
+	}
+	if err != nil {
+		return errors.New("expected " + string(key.Kind) + ", got " + strconv.Quote(value))
+	}
+	return nil
+}
+
+// Describe returns where the value came from, e.g. "env CODEVIDEO_WORK_DIR".
+func (v Value) Describe() string {
+	if v.Origin == "" {
+		return string(v.Source)
+	}
+	return fmt.Sprintf("%s %s", v.Source, v.Origin)
+}
+
+// Display returns the value for printing, with secrets masked.
+func (v Value) Display() string {
+	if v.Secret && v.Value != "" {
+		return "********"
+	}
+	return v.Value
+}
@@ -0,0 +1,121 @@
+package settings
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+func writeFile(t *testing.T, path string, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// isolate runs the test in an empty working and user config directory with
+// none of the keys' environment variables set.
+func isolate(t *testing.T) (projectDir string, userDir string) {
+	t.Helper()
+	projectDir, userDir = t.TempDir(), t.TempDir()
+	t.Chdir(projectDir)
+	t.Setenv("XDG_CONFIG_HOME", userDir)
+	t.Setenv("CODEVIDEO_CONFIG", "")
+	for _, key := range Keys {
+		t.Setenv(key.Env, "")
+	}
+	return projectDir, userDir
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	projectDir, userDir := isolate(t)
+	writeFile(t, filepath.Join(userDir, "codevideo", "codevideo.toml"), `
+[render]
+resolution = "4K"
+orientation = "portrait"
+stall_timeout = "10m"
+
+[server]
+max_concurrent_jobs = 8
+`)
+	writeFile(t, filepath.Join(projectDir, "codevideo.yaml"), `
+render:
+  orientation: landscape
+  stall_timeout: 5m
+server:
+  max_concurrent_jobs: 4
+`)
+	t.Setenv("CODEVIDEO_STALL_TIMEOUT", "7m")
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.String("orientation", "", "")
+	flags.Parse([]string{"--orientation", "portrait"})
+
+	config, err := Load(flags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]Source{
+		"render.resolution":          SourceUser,
+		"render.orientation":         SourceFlag,
+		"render.stall_timeout":       SourceEnv,
+		"server.max_concurrent_jobs": SourceProject,
+		"server.api_addr":            SourceDefault,
+	}
+	for _, value := range config.Values {
+		if source, ok := want[value.Name]; ok && value.Source != source {
+			t.Errorf("%s = %q from %s, want from %s", value.Name, value.Value, value.Source, source)
+		}
+	}
+	if got := config.Get("server.max_concurrent_jobs"); got != "4" {
+		t.Errorf("max_concurrent_jobs = %q, want 4", got)
+	}
+
+	config.Apply()
+	if got := os.Getenv("CODEVIDEO_RESOLUTION"); got != "4K" {
+		t.Errorf("CODEVIDEO_RESOLUTION after Apply = %q, want 4K", got)
+	}
+	if got := os.Getenv("CODEVIDEO_STALL_TIMEOUT"); got != "7m" {
+		t.Errorf("Apply overwrote the environment: CODEVIDEO_STALL_TIMEOUT = %q", got)
+	}
+	reloaded, err := Load(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, value := range reloaded.Values {
+		if value.Name == "render.resolution" && value.Source != SourceUser {
+			t.Errorf("after Apply, render.resolution is attributed to %s", value.Describe())
+		}
+	}
+}
+
+func TestReadFileReportsEveryProblem(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "codevideo.yaml")
+	writeFile(t, path, `
+render:
+  resolutin: 4K
+server:
+  max_concurrent_jobs: many
+`)
+	_, err := ReadFile(path)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, want := range []string{"unknown setting render.resolutin", "server.max_concurrent_jobs: expected int"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+}
+
+func TestValueDisplayMasksSecrets(t *testing.T) {
+	key, _ := Lookup("tts.elevenlabs_api_key")
+	if got := (Value{Key: key, Value: "sk-123"}).Display(); got == "sk-123" {
+		t.Error("secret value was displayed")
+	}
+}
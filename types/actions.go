@@ -0,0 +1,134 @@
+package types
+
+// ActionNames are the action names the bundled CodeVideo player understands,
+// mirroring AllActions in codevideo-types. They are the enum of Action.Name in
+// the published JSON Schemas.
+var ActionNames = []string{
+	// Author
+	"author-speak-before",
+	"author-speak-after",
+	"author-speak-during",
+	"author-wait",
+	// Editor
+	"editor-type",
+	"editor-save",
+	"editor-arrow-up",
+	"editor-arrow-down",
+	"editor-arrow-left",
+	"editor-arrow-right",
+	"editor-enter",
+	"editor-tab",
+	"editor-backspace",
+	"editor-space",
+	"editor-highlight-code",
+	"editor-delete-line",
+	"editor-command-left",
+	"editor-command-right",
+	"editor-command-d",
+	"editor-command-c",
+	"editor-command-v",
+	"editor-shift-down-arrow",
+	"editor-shift-up-arrow",
+	"editor-scroll-up",
+	"editor-scroll-down",
+	"editor-show-context-menu",
+	"editor-hide-context-menu",
+	// Mouse
+	"mouse-move-file-explorer",
+	"mouse-move-editor",
+	"mouse-move-editor-tab",
+	"mouse-move-editor-tab-close",
+	"mouse-move-terminal",
+	"mouse-move-terminal-tab",
+	"mouse-move-terminal-tab-close",
+	"mouse-move-file-explorer-file",
+	"mouse-move-file-explorer-folder",
+	"mouse-move-to-coordinates-pixels",
+	"mouse-move-to-coordinates-percent",
+	"mouse-move-file-explorer-context-menu-new-file",
+	"mouse-move-file-explorer-context-menu-new-folder",
+	"mouse-move-file-explorer-file-context-menu-rename",
+	"mouse-move-file-explorer-file-context-menu-delete",
+	"mouse-move-file-explorer-folder-context-menu-new-file",
+	"mouse-move-file-explorer-folder-context-menu-new-folder",
+	"mouse-move-file-explorer-folder-context-menu-rename",
+	"mouse-move-file-explorer-folder-context-menu-delete",
+	"mouse-move-editor-context-menu-go-to-definition",
+	"mouse-move-editor-context-menu-cut",
+	"mouse-move-editor-context-menu-copy",
+	"mouse-move-editor-context-menu-paste",
+	"mouse-move-unsaved-changes-dialog-button-save",
+	"mouse-move-unsaved-changes-dialog-button-dont-save",
+	"mouse-move-unsaved-changes-dialog-button-cancel",
+	"mouse-move",
+	"mouse-left-click",
+	"mouse-double-left-click",
+	"mouse-triple-left-click",
+	"mouse-right-click",
+	"mouse-right-double-click",
+	"mouse-right-triple-click",
+	// File explorer
+	"file-explorer-set-file-contents",
+	"file-explorer-set-file-caret-position",
+	"file-explorer-create-file",
+	"file-explorer-open-file",
+	"file-explorer-close-file",
+	"file-explorer-rename-file",
+	"file-explorer-delete-file",
+	"file-explorer-move-file",
+	"file-explorer-copy-file",
+	"file-explorer-create-folder",
+	"file-explorer-expand-folder",
+	"file-explorer-collapse-folder",
+	"file-explorer-rename-folder",
+	"file-explorer-delete-folder",
+	"file-explorer-toggle-folder",
+	"file-explorer-move-folder",
+	"file-explorer-copy-folder",
+	"file-explorer-show-context-menu",
+	"file-explorer-hide-context-menu",
+	"file-explorer-show-file-context-menu",
+	"file-explorer-hide-file-context-menu",
+	"file-explorer-show-folder-context-menu",
+	"file-explorer-hide-folder-context-menu",
+	"file-explorer-show-new-file-input",
+	"file-explorer-show-new-folder-input",
+	"file-explorer-hide-new-file-input",
+	"file-explorer-hide-new-folder-input",
+	"file-explorer-type-new-file-input",
+	"file-explorer-clear-new-file-input",
+	"file-explorer-type-new-folder-input",
+	"file-explorer-clear-new-folder-input",
+	"file-explorer-rename-file-draft-state",
+	"file-explorer-rename-folder-draft-state",
+	"file-explorer-type-rename-file-input",
+	"file-explorer-type-rename-folder-input",
+	"file-explorer-enter-new-file-input",
+	"file-explorer-enter-new-folder-input",
+	"file-explorer-enter-rename-file-input",
+	"file-explorer-enter-rename-folder-input",
+	// Terminal
+	"terminal-open",
+	"terminal-type",
+	"terminal-arrow-up",
+	"terminal-arrow-down",
+	"terminal-arrow-left",
+	"terminal-arrow-right",
+	"terminal-enter",
+	"terminal-tab",
+	"terminal-backspace",
+	"terminal-space",
+	"terminal-command-left",
+	"terminal-command-right",
+	"terminal-command-c",
+	"terminal-command-v",
+	"terminal-set-output",
+	"terminal-set-prompt",
+	"terminal-set-present-working-directory",
+	// External browser and web preview
+	"external-browser",
+	"external-browser-scroll",
+	"external-web-preview",
+	// Slides
+	"slide-display",
+}
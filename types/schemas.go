@@ -0,0 +1,57 @@
+package types
+
+import (
+	"strings"
+
+	"github.com/codevideo/codevideo-cli/schema"
+)
+
+func init() {
+	schema.RegisterEnum("action", ActionNames)
+}
+
+// Project types, as declared by a project's "type" property or --project-type.
+const (
+	ProjectTypeCourse  = "course"
+	ProjectTypeLesson  = "lesson"
+	ProjectTypeActions = "actions"
+)
+
+// SchemaType is a type with a published JSON Schema, kept in schema/File.
+// Objects with a Discriminator may declare it in a "type" property.
+type SchemaType struct {
+	Name          string
+	File          string
+	Value         interface{}
+	Discriminator string
+}
+
+// Schema generates the type's JSON Schema.
+func (s SchemaType) Schema() *schema.Schema {
+	generated := schema.For(s.Value, s.Name)
+	if s.Discriminator != "" {
+		generated.Properties["type"] = &schema.Schema{Type: "string", Enum: []string{s.Discriminator}}
+	}
+	return generated
+}
+
+// SchemaTypes are the project formats, the render manifest and the IDE config
+// file. `codevideo schema` prints their schemas.
+var SchemaTypes = []SchemaType{
+	{Name: "Action", File: "action.schema.json", Value: Action{}},
+	{Name: "Actions", File: "actions.schema.json", Value: []Action{}},
+	{Name: "Lesson", File: "lesson.schema.json", Value: Lesson{}, Discriminator: ProjectTypeLesson},
+	{Name: "Course", File: "course.schema.json", Value: Course{}, Discriminator: ProjectTypeCourse},
+	{Name: "CodeVideoManifest", File: "codevideo-manifest.schema.json", Value: CodeVideoManifest{}},
+	{Name: "CodeVideoIDEProps", File: "codevideo-ide-props.schema.json", Value: CodeVideoIDEProps{}},
+}
+
+// LookupSchemaType returns the schema type with the given name, ignoring case.
+func LookupSchemaType(name string) (SchemaType, bool) {
+	for _, s := range SchemaTypes {
+		if strings.EqualFold(s.Name, name) {
+			return s, true
+		}
+	}
+	return SchemaType{}, false
+}
@@ -1,16 +1,164 @@
 package types
 
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
 type CodeVideoManifest struct {
-	Environment        string             `json:"environment"`
-	UserID             string             `json:"userId"`
-	UUID               string             `json:"uuid"`
-	Actions            []Action           `json:"actions,omitempty"`
-	Lesson             Lesson             `json:"lesson,omitempty"`
-	CurrentLessonIndex int                `json:"currentLessonIndex,omitempty"`
-	AudioItems         []AudioItem        `json:"audioItems"`
-	FontSizePx         int                `json:"fontSizePx,omitempty"`
-	Error              string             `json:"error,omitempty"`
-	CodeVideoIDEProps  *CodeVideoIDEProps `json:"codeVideoIDEProps,omitempty"`
+	Environment        string                 `json:"environment"`
+	UserID             string                 `json:"userId"`
+	UUID               string                 `json:"uuid" jsonschema:"required"`
+	Actions            []Action               `json:"actions,omitempty"`
+	Lesson             Lesson                 `json:"lesson,omitempty"`
+	CourseName         string                 `json:"courseName,omitempty"`
+	CurrentLessonIndex int                    `json:"currentLessonIndex,omitempty"`
+	AudioItems         []AudioItem            `json:"audioItems"`
+	FontSizePx         int                    `json:"fontSizePx,omitempty"`
+	Locale             string                 `json:"locale,omitempty"` // language for notification emails, e.g. "de"
+	ActionTimings      []ActionTiming         `json:"actionTimings,omitempty"`
+	Retry              map[string]RetryPolicy `json:"retry,omitempty"`    // per-stage overrides of the server's retry policy
+	Attempts           []JobAttempt           `json:"attempts,omitempty"` // history of stage attempts
+	Error              string                 `json:"error,omitempty"`
+	NotifyError        string                 `json:"notifyError,omitempty"` // set when the video was delivered but the user couldn't be notified
+	Interrupted        bool                   `json:"interrupted,omitempty"` // set when a shutdown stopped the job; cleared when it is requeued
+	Billing            *JobBilling            `json:"billing,omitempty"`     // tokens held for the job in serve mode
+	Render             *RenderSettings        `json:"render,omitempty"`      // per-job render settings; empty fields use the server's defaults
+	CodeVideoIDEProps  *CodeVideoIDEProps     `json:"codeVideoIDEProps,omitempty"`
+}
+
+// JobBilling records the tokens reserved for a job and whether they were
+// committed (job delivered) or released (job failed).
+type JobBilling struct {
+	Tokens int    `json:"tokens"`
+	State  string `json:"state"` // reserved | committed | released
+}
+
+// Encoding profiles trade encoding time and file size against quality.
+const (
+	EncodingStandard = "standard"
+	EncodingHigh     = "high"
+	EncodingDraft    = "draft"
+)
+
+// DefaultFrameRate is the frame rate of jobs that don't set one.
+const DefaultFrameRate = 60
+
+// resolutionPresets are the landscape sizes of the named resolutions; portrait
+// swaps width and height.
+var resolutionPresets = map[string][2]int{
+	"720p":  {1280, 720},
+	"1080p": {1920, 1080},
+	"1440p": {2560, 1440},
+	"4K":    {3840, 2160},
+}
+
+// aspectRatios are the fixed sizes of the social media formats. The
+// orientation doesn't apply to them.
+var aspectRatios = map[string][2]int{
+	"1:1": {1080, 1080},
+	"4:5": {1080, 1350},
+}
+
+// ResolutionHelp lists the accepted resolution values, for error messages.
+const ResolutionHelp = "use 720p, 1080p, 1440p or 4K, 1:1 or 4:5, or a custom WIDTHxHEIGHT such as 1280x960"
+
+// Bounds of custom resolutions and frame rates.
+const (
+	minDimension = 240
+	maxDimension = 7680
+	minFrameRate = 1
+	maxFrameRate = 120
+)
+
+// RenderSettings controls how a job is recorded and encoded.
+type RenderSettings struct {
+	Resolution      string `json:"resolution,omitempty"`      // 720p | 1080p | 1440p | 4K | 1:1 | 4:5 | WIDTHxHEIGHT
+	Orientation     string `json:"orientation,omitempty"`     // landscape | portrait
+	FrameRate       int    `json:"frameRate,omitempty"`       // frames per second of the recording and the mp4
+	Debug           bool   `json:"debug,omitempty"`           // record with a visible (non-headless) browser
+	EncodingProfile string `json:"encodingProfile,omitempty"` // standard | high | draft
+}
+
+// Resolve returns the settings with empty fields taken from defaults. A nil
+// receiver resolves to the defaults.
+func (r *RenderSettings) Resolve(defaults RenderSettings) RenderSettings {
+	if r == nil {
+		return defaults
+	}
+	resolved := *r
+	if resolved.Resolution == "" {
+		resolved.Resolution = defaults.Resolution
+	}
+	if resolved.Orientation == "" {
+		resolved.Orientation = defaults.Orientation
+	}
+	if resolved.FrameRate == 0 {
+		resolved.FrameRate = defaults.FrameRate
+	}
+	if resolved.EncodingProfile == "" {
+		resolved.EncodingProfile = defaults.EncodingProfile
+	}
+	resolved.Debug = resolved.Debug || defaults.Debug
+	return resolved
+}
+
+// Validate checks that every set field has a supported value.
+func (r RenderSettings) Validate() error {
+	if r.Resolution != "" {
+		if _, _, err := r.Dimensions(); err != nil {
+			return err
+		}
+	}
+	if r.Orientation != "" && r.Orientation != "landscape" && r.Orientation != "portrait" {
+		return fmt.Errorf("unsupported orientation %q (use landscape or portrait)", r.Orientation)
+	}
+	if r.FrameRate != 0 && (r.FrameRate < minFrameRate || r.FrameRate > maxFrameRate) {
+		return fmt.Errorf("unsupported frame rate %d (use %d to %d frames per second)", r.FrameRate, minFrameRate, maxFrameRate)
+	}
+	switch r.EncodingProfile {
+	case "", EncodingStandard, EncodingHigh, EncodingDraft:
+	default:
+		return fmt.Errorf("unsupported encoding profile %q (use %s, %s or %s)", r.EncodingProfile, EncodingStandard, EncodingHigh, EncodingDraft)
+	}
+	return nil
+}
+
+// Dimensions returns the video's width and height in pixels. Presets are
+// 1080p when no resolution is set, and portrait presets swap width and height.
+func (r RenderSettings) Dimensions() (width int, height int, err error) {
+	resolution := r.Resolution
+	if resolution == "" {
+		resolution = "1080p"
+	}
+	if size, ok := resolutionPresets[resolution]; ok {
+		if r.Orientation == "portrait" {
+			return size[1], size[0], nil
+		}
+		return size[0], size[1], nil
+	}
+	if size, ok := aspectRatios[resolution]; ok {
+		return size[0], size[1], nil
+	}
+
+	w, h, ok := strings.Cut(strings.ToLower(resolution), "x")
+	if !ok {
+		return 0, 0, fmt.Errorf("unsupported resolution %q (%s)", resolution, ResolutionHelp)
+	}
+	width, errW := strconv.Atoi(w)
+	height, errH := strconv.Atoi(h)
+	if errW != nil || errH != nil {
+		return 0, 0, fmt.Errorf("unsupported resolution %q (%s)", resolution, ResolutionHelp)
+	}
+	if width < minDimension || height < minDimension || width > maxDimension || height > maxDimension {
+		return 0, 0, fmt.Errorf("unsupported resolution %q: width and height must be between %d and %d pixels", resolution, minDimension, maxDimension)
+	}
+	if width%2 != 0 || height%2 != 0 {
+		return 0, 0, fmt.Errorf("unsupported resolution %q: width and height must be even for H.264", resolution)
+	}
+	return width, height, nil
 }
 
 // Configuration holds all CLI configuration
@@ -23,8 +171,8 @@ type Configuration struct {
 }
 
 type Action struct {
-	Name  string `json:"name"`
-	Value string `json:"value"`
+	Name  string `json:"name" jsonschema:"required,enum=@action"`
+	Value string `json:"value" jsonschema:"required"`
 }
 
 // IsValidAction checks if an action has valid Name and Value fields
@@ -32,10 +180,37 @@ func IsValidAction(action Action) bool {
 	return action.Name != "" && action.Value != ""
 }
 
+// ActionTiming records when an action played, in milliseconds from the start
+// of the recording. Written back to the manifest after recording for captions and chapters.
+type ActionTiming struct {
+	Index   int    `json:"index"`
+	Name    string `json:"name"`
+	StartMs int64  `json:"startMs"`
+	EndMs   int64  `json:"endMs"`
+}
+
+// RetryPolicy controls how a pipeline stage (audio, recording, encoding,
+// upload, notify) is retried. Zero fields fall back to the server's policy.
+type RetryPolicy struct {
+	MaxAttempts       int     `json:"maxAttempts,omitempty"`
+	BackoffMs         int     `json:"backoffMs,omitempty"`
+	BackoffMultiplier float64 `json:"backoffMultiplier,omitempty"`
+}
+
+// JobAttempt records one attempt of a pipeline stage.
+type JobAttempt struct {
+	Stage      string    `json:"stage"`
+	Attempt    int       `json:"attempt"`
+	StartedAt  time.Time `json:"startedAt"`
+	DurationMs int64     `json:"durationMs"`
+	Error      string    `json:"error,omitempty"`
+}
+
 // AudioItem represents an audio item in a CodeVideo project
 type AudioItem struct {
-	Text   string `json:"text"`
-	Mp3Url string `json:"mp3Url"`
+	Text       string `json:"text"`
+	Mp3Url     string `json:"mp3Url"`
+	DurationMs int64  `json:"durationMs,omitempty"` // estimated playing time, used to bound the recording runtime
 }
 
 // Project is a generic interface for all project types
@@ -63,11 +238,11 @@ type CourseSnapshot map[string]interface{}
 // "name" (not "title") plus "id" and optional "finalSnapshot".
 type Lesson struct {
 	ID              string         `json:"id"`
-	Name            string         `json:"name"`
+	Name            string         `json:"name" jsonschema:"required"`
 	Description     string         `json:"description"`
 	InitialSnapshot CourseSnapshot `json:"initialSnapshot"`
 	FinalSnapshot   CourseSnapshot `json:"finalSnapshot,omitempty"`
-	Actions         []Action       `json:"actions"`
+	Actions         []Action       `json:"actions" jsonschema:"required"`
 }
 
 // GetType implements the Project interface
@@ -80,10 +255,10 @@ func (l Lesson) GetType() string {
 // and "primaryLanguage".
 type Course struct {
 	ID              string   `json:"id"`
-	Name            string   `json:"name"`
+	Name            string   `json:"name" jsonschema:"required"`
 	Description     string   `json:"description"`
 	PrimaryLanguage string   `json:"primaryLanguage"`
-	Lessons         []Lesson `json:"lessons"`
+	Lessons         []Lesson `json:"lessons" jsonschema:"required"`
 }
 
 // GetType implements the Project interface
@@ -100,31 +275,34 @@ type CodeVideoUserMetadata struct {
 	TokensPerCycle     int    `json:"tokensPerCycle"`
 }
 
+// CodeVideoIDEProps are the CodeVideoIDE React props, read from the --config
+// file. The jsonschema tags are enforced when it's loaded and published in
+// schema/codevideo-ide-props.schema.json.
 type CodeVideoIDEProps struct {
-	Theme                    string             `json:"theme"` // 'light' | 'dark'
-	Project                  Project            `json:"project"`
-	Mode                     string             `json:"mode"` // GUIMode equivalent
+	Theme                    string             `json:"theme" jsonschema:"required,enum=light|dark"`
+	Project                  Project            `json:"project" jsonschema:"type=null"`            // set from --project, not the config file
+	Mode                     string             `json:"mode" jsonschema:"enum=step|replay|record"` // GUIMode equivalent
 	AllowFocusInEditor       bool               `json:"allowFocusInEditor"`
-	CurrentActionIndex       int                `json:"currentActionIndex"`
-	CurrentLessonIndex       *int               `json:"currentLessonIndex"`
-	DefaultLanguage          string             `json:"defaultLanguage"`
+	CurrentActionIndex       int                `json:"currentActionIndex" jsonschema:"minimum=0"`
+	CurrentLessonIndex       *int               `json:"currentLessonIndex" jsonschema:"minimum=0"`
+	DefaultLanguage          string             `json:"defaultLanguage" jsonschema:"required,minLength=1"`
 	IsExternalBrowserStepUrl *string            `json:"isExternalBrowserStepUrl"`
 	IsSoundOn                bool               `json:"isSoundOn"`
 	WithCaptions             bool               `json:"withCaptions"`
 	SpeakActionAudios        []SpeakActionAudio `json:"speakActionAudios"`
-	FileExplorerWidth        *int               `json:"fileExplorerWidth,omitempty"`
-	TerminalHeight           *int               `json:"terminalHeight,omitempty"`
-	MouseColor               *string            `json:"mouseColor,omitempty"`
-	FontSizePx               *int               `json:"fontSizePx,omitempty"`
+	FileExplorerWidth        *int               `json:"fileExplorerWidth,omitempty" jsonschema:"minimum=0,maximum=3840"`
+	TerminalHeight           *int               `json:"terminalHeight,omitempty" jsonschema:"minimum=0,maximum=2160"`
+	MouseColor               *string            `json:"mouseColor,omitempty" jsonschema:"pattern=^(#[0-9a-fA-F]{3}|#[0-9a-fA-F]{6}|#[0-9a-fA-F]{8}|rgba?\\([0-9., %]+\\))$"`
+	FontSizePx               *int               `json:"fontSizePx,omitempty" jsonschema:"minimum=6,maximum=72"`
 	IsEmbedMode              *bool              `json:"isEmbedMode,omitempty"`
 	IsFileExplorerVisible    *bool              `json:"isFileExplorerVisible,omitempty"`
 	IsTerminalVisible        *bool              `json:"isTerminalVisible,omitempty"`
-	KeyboardTypingPauseMs    *int               `json:"keyboardTypingPauseMs,omitempty"`
-	StandardPauseMs          *int               `json:"standardPauseMs,omitempty"`
-	LongPauseMs              *int               `json:"longPauseMs,omitempty"`
+	KeyboardTypingPauseMs    *int               `json:"keyboardTypingPauseMs,omitempty" jsonschema:"minimum=0,maximum=5000"`
+	StandardPauseMs          *int               `json:"standardPauseMs,omitempty" jsonschema:"minimum=0,maximum=60000"`
+	LongPauseMs              *int               `json:"longPauseMs,omitempty" jsonschema:"minimum=0,maximum=120000"`
 }
 
 type SpeakActionAudio struct {
-	Text   string `json:"text"`
-	Mp3Url string `json:"mp3Url"`
+	Text   string `json:"text" jsonschema:"required"`
+	Mp3Url string `json:"mp3Url" jsonschema:"required"`
 }
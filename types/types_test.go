@@ -90,3 +90,49 @@ func TestLessonRoundTripEmitsName(t *testing.T) {
 		t.Errorf("round-tripped lesson should NOT emit a \"title\" field: %s", out)
 	}
 }
+
+func TestRenderSettingsResolve(t *testing.T) {
+	defaults := RenderSettings{Resolution: "1080p", Orientation: "landscape", EncodingProfile: EncodingStandard}
+
+	var unset *RenderSettings
+	if got := unset.Resolve(defaults); got != defaults {
+		t.Errorf("nil settings resolved to %+v", got)
+	}
+	job := &RenderSettings{Orientation: "portrait", EncodingProfile: EncodingHigh}
+	want := RenderSettings{Resolution: "1080p", Orientation: "portrait", EncodingProfile: EncodingHigh}
+	if got := job.Resolve(defaults); got != want {
+		t.Errorf("Resolve = %+v, want %+v", got, want)
+	}
+	if err := (RenderSettings{Resolution: "8K"}).Validate(); err == nil {
+		t.Error("Validate accepted 8K")
+	}
+}
+
+func TestRenderSettingsDimensions(t *testing.T) {
+	cases := []struct {
+		settings      RenderSettings
+		width, height int
+	}{
+		{RenderSettings{}, 1920, 1080},
+		{RenderSettings{Resolution: "720p"}, 1280, 720},
+		{RenderSettings{Resolution: "1440p", Orientation: "portrait"}, 1440, 2560},
+		{RenderSettings{Resolution: "4K", Orientation: "landscape"}, 3840, 2160},
+		{RenderSettings{Resolution: "1:1", Orientation: "portrait"}, 1080, 1080},
+		{RenderSettings{Resolution: "4:5"}, 1080, 1350},
+		{RenderSettings{Resolution: "1280x960"}, 1280, 960},
+	}
+	for _, c := range cases {
+		width, height, err := c.settings.Dimensions()
+		if err != nil || width != c.width || height != c.height {
+			t.Errorf("%+v.Dimensions() = %d, %d, %v; want %d, %d", c.settings, width, height, err, c.width, c.height)
+		}
+	}
+	for _, invalid := range []string{"8K", "1280x", "1281x720", "100x100", "wide"} {
+		if err := (RenderSettings{Resolution: invalid}).Validate(); err == nil {
+			t.Errorf("Validate accepted resolution %q", invalid)
+		}
+	}
+	if err := (RenderSettings{FrameRate: 240}).Validate(); err == nil {
+		t.Error("Validate accepted 240 fps")
+	}
+}
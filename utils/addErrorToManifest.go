@@ -6,29 +6,45 @@ import (
 )
 
 func AddErrorToManifest(manifestPath string, error string) {
+	SetManifestField(manifestPath, "error", error)
+}
+
+// SetManifestField sets a top-level key of the manifest file to value,
+// preserving all other keys as they are on disk.
+func SetManifestField(manifestPath string, key string, value interface{}) error {
+	return updateManifest(manifestPath, func(manifest map[string]interface{}) {
+		manifest[key] = value
+	})
+}
+
+// RemoveManifestField deletes a top-level key from the manifest file.
+func RemoveManifestField(manifestPath string, key string) error {
+	return updateManifest(manifestPath, func(manifest map[string]interface{}) {
+		delete(manifest, key)
+	})
+}
+
+func updateManifest(manifestPath string, update func(manifest map[string]interface{})) error {
 	// Read the manifest file.
 	manifestBytes, err := os.ReadFile(manifestPath)
 	if err != nil {
-		return
+		return err
 	}
 
 	// Unmarshal the manifest JSON.
 	var manifest map[string]interface{}
 	if err := json.Unmarshal(manifestBytes, &manifest); err != nil {
-		return
+		return err
 	}
 
-	// Add the error key and value.
-	manifest["error"] = error
+	update(manifest)
 
 	// Marshal the manifest back to JSON.
 	updatedManifestBytes, err := json.Marshal(manifest)
 	if err != nil {
-		return
+		return err
 	}
 
 	// Write the updated manifest back to the file.
-	if err := os.WriteFile(manifestPath, updatedManifestBytes, 0644); err != nil {
-		return
-	}
+	return os.WriteFile(manifestPath, updatedManifestBytes, 0644)
 }
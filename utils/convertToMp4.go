@@ -2,6 +2,7 @@ package utils
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
@@ -10,15 +11,47 @@ import (
 	"strconv"
 	"strings"
 
-	"github.com/codevideo/codevideo-cli/cli/renderer"
+	"github.com/codevideo/codevideo-cli/progress"
+	"github.com/codevideo/codevideo-cli/types"
 	log "github.com/sirupsen/logrus"
 )
 
+// encodingProfile is the ffmpeg preset, quality level (CRF) and audio bitrate
+// of an encoding profile.
+type encodingProfile struct {
+	preset       string
+	crf          string
+	audioBitrate string
+}
+
+var encodingProfiles = map[string]encodingProfile{
+	types.EncodingStandard: {preset: "fast", crf: "18", audioBitrate: "384k"},
+	types.EncodingHigh:     {preset: "slow", crf: "16", audioBitrate: "384k"},
+	types.EncodingDraft:    {preset: "veryfast", crf: "28", audioBitrate: "128k"},
+}
+
 // ConvertToMp4 converts a given input file to an MP4 file with the specified output filename.
 // It constructs the ffmpeg command with options to overwrite output (-y), use the input (-i),
-// set the video codec, preset, quality level, frame rate, audio codec, and audio bitrate.
-func ConvertToMp4(input, output string, mode string) error {
-	renderer.RenderProgressToConsole(95, "Converting webm to mp4...")
+// set the video codec, preset, quality level, frame rate, audio codec, and audio bitrate;
+// the preset, quality and bitrate come from the render settings' encoding profile (standard
+// when empty) and the frame rate from theirs (types.DefaultFrameRate when zero).
+// Progress is published as encoding events for the given job.
+// The conversion is stopped when ctx is cancelled.
+func ConvertToMp4(ctx context.Context, input, output string, jobUUID string, render types.RenderSettings) error {
+	profileName := render.EncodingProfile
+	if profileName == "" {
+		profileName = types.EncodingStandard
+	}
+	profile, ok := encodingProfiles[profileName]
+	if !ok {
+		return fmt.Errorf("unknown encoding profile %q", profileName)
+	}
+	frameRate := render.FrameRate
+	if frameRate == 0 {
+		frameRate = types.DefaultFrameRate
+	}
+
+	progress.Publish(progress.Event{JobUUID: jobUUID, Stage: progress.StageEncoding, Percent: 95, Message: "Converting webm to mp4..."})
 
 	// Convert input and output to absolute paths if they aren't already
 	inputAbs, err := filepath.Abs(input)
@@ -50,15 +83,15 @@ func ConvertToMp4(input, output string, mode string) error {
 	}
 
 	// Construct the ffmpeg command with the -progress flag.
-	cmd := exec.Command(ffmpegPath,
+	cmd := exec.CommandContext(ctx, ffmpegPath,
 		"-y",           // Overwrite output if exists
 		"-i", inputAbs, // Input file (absolute path)
 		"-c:v", "libx264", // Video codec
-		"-preset", "fast", // Encoding preset
-		"-crf", "18", // Quality level
-		"-r", "60", // Frame rate
+		"-preset", profile.preset, // Encoding preset
+		"-crf", profile.crf, // Quality level
+		"-r", strconv.Itoa(frameRate), // Frame rate
 		"-c:a", "aac", // Audio codec
-		"-b:a", "384k", // Audio bitrate
+		"-b:a", profile.audioBitrate, // Audio bitrate
 		"-progress", "pipe:1", // Send progress info to stdout
 		outputAbs, // Output file (absolute path)
 	)
@@ -94,10 +127,7 @@ func ConvertToMp4(input, output string, mode string) error {
 					if ffmpegProgress, err := strconv.ParseFloat(pStr, 64); err == nil {
 						// Normalize the ffmpeg progress (0-100) to overall progress (80-100).
 						normalizedProgress := 80.0 + (ffmpegProgress/100.0)*20.0
-						// Update the progress bar with the normalized progress.
-						if mode == "cli" {
-							renderer.RenderProgressToConsole(normalizedProgress, "Converting webm to mp4...")
-						}
+						progress.Publish(progress.Event{JobUUID: jobUUID, Stage: progress.StageEncoding, Percent: normalizedProgress, Message: "Converting webm to mp4..."})
 					}
 				}
 			}
@@ -110,7 +140,7 @@ func ConvertToMp4(input, output string, mode string) error {
 		return err
 	}
 
-	renderer.RenderProgressToConsole(100, "")
+	progress.Publish(progress.Event{JobUUID: jobUUID, Stage: progress.StageEncoding, Percent: 100})
 
 	return nil
 }
@@ -1,11 +1,14 @@
 package utils
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
 	"testing"
+
+	"github.com/codevideo/codevideo-cli/types"
 )
 
 func TestResolveFFmpegPathOverride(t *testing.T) {
@@ -48,3 +51,10 @@ func TestResolveFFmpegPathRejectsNonExecutableOverride(t *testing.T) {
 		t.Fatalf("expected executable error, got %v", err)
 	}
 }
+
+func TestConvertToMp4RejectsUnknownProfile(t *testing.T) {
+	err := ConvertToMp4(context.Background(), "in.webm", "out.mp4", "job", types.RenderSettings{EncodingProfile: "lossless"})
+	if err == nil || !strings.Contains(err.Error(), "unknown encoding profile") {
+		t.Fatalf("expected unknown profile error, got %v", err)
+	}
+}
@@ -0,0 +1,17 @@
+package utils
+
+import (
+	"net"
+	"strconv"
+)
+
+// IsPortAvailable checks if a port is available by attempting to listen on it
+func IsPortAvailable(port int) bool {
+	ln, err := net.Listen("tcp", ":"+strconv.Itoa(port))
+	if err != nil {
+		// Port is likely in use
+		return false
+	}
+	ln.Close()
+	return true
+}
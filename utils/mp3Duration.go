@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+var (
+	mp3BitratesV1 = [16]int{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 0}
+	mp3BitratesV2 = [16]int{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0}
+	mp3SampleRate = map[byte][3]int{
+		3: {44100, 48000, 32000}, // MPEG 1
+		2: {22050, 24000, 16000}, // MPEG 2
+		0: {11025, 12000, 8000},  // MPEG 2.5
+	}
+)
+
+// EstimateMp3Duration returns the playing time of MPEG layer III audio. It
+// uses the Xing/Info frame count when present (VBR) and otherwise assumes a
+// constant bitrate, which is what the TTS providers return.
+func EstimateMp3Duration(data []byte) (time.Duration, error) {
+	offset := 0
+	// skip an ID3v2 tag: "ID3", version (2 bytes), flags, then a 4 byte syncsafe size
+	if len(data) >= 10 && bytes.Equal(data[:3], []byte("ID3")) {
+		size := int(data[6]&0x7f)<<21 | int(data[7]&0x7f)<<14 | int(data[8]&0x7f)<<7 | int(data[9]&0x7f)
+		offset = 10 + size
+	}
+
+	// find the first frame sync
+	for ; offset+4 <= len(data); offset++ {
+		if data[offset] == 0xff && data[offset+1]&0xe0 == 0xe0 {
+			break
+		}
+	}
+	if offset+4 > len(data) {
+		return 0, fmt.Errorf("no mp3 frame found")
+	}
+
+	header := data[offset : offset+4]
+	version := (header[1] >> 3) & 0x03
+	layer := (header[1] >> 1) & 0x03
+	bitrateIndex := header[2] >> 4
+	sampleRateIndex := (header[2] >> 2) & 0x03
+	channelMode := header[3] >> 6
+	if version == 1 || layer != 1 || sampleRateIndex == 3 {
+		return 0, fmt.Errorf("unsupported mp3 frame header %x", header)
+	}
+
+	sampleRate := mp3SampleRate[version][sampleRateIndex]
+	samplesPerFrame := 1152
+	bitrate := mp3BitratesV1[bitrateIndex]
+	sideInfo := 32
+	if channelMode == 3 {
+		sideInfo = 17
+	}
+	if version != 3 {
+		samplesPerFrame = 576
+		bitrate = mp3BitratesV2[bitrateIndex]
+		sideInfo = 17
+		if channelMode == 3 {
+			sideInfo = 9
+		}
+	}
+
+	// VBR files carry the total frame count in a Xing/Info header in the first frame
+	xing := offset + 4 + sideInfo
+	if xing+12 <= len(data) {
+		tag := string(data[xing : xing+4])
+		flags := binary.BigEndian.Uint32(data[xing+4 : xing+8])
+		if (tag == "Xing" || tag == "Info") && flags&0x1 != 0 {
+			frames := binary.BigEndian.Uint32(data[xing+8 : xing+12])
+			return time.Duration(float64(frames) * float64(samplesPerFrame) / float64(sampleRate) * float64(time.Second)), nil
+		}
+	}
+
+	if bitrate == 0 {
+		return 0, fmt.Errorf("free-format mp3 is not supported")
+	}
+	audioBytes := len(data) - offset
+	return time.Duration(float64(audioBytes) * 8 / float64(bitrate*1000) * float64(time.Second)), nil
+}
@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+func TestEstimateMp3DurationCBR(t *testing.T) {
+	// ID3v2 tag (size 16) followed by MPEG 1 layer III, 128 kbps, 44.1 kHz frames
+	data := []byte{'I', 'D', '3', 4, 0, 0, 0, 0, 0, 16}
+	data = append(data, make([]byte, 16)...)
+	audio := make([]byte, 16000) // one second at 128 kbps
+	copy(audio, []byte{0xff, 0xfb, 0x90, 0x00})
+	data = append(data, audio...)
+
+	got, err := EstimateMp3Duration(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != time.Second {
+		t.Errorf("EstimateMp3Duration() = %s, want 1s", got)
+	}
+}
+
+func TestEstimateMp3DurationXing(t *testing.T) {
+	// MPEG 1 layer III, 44.1 kHz, stereo: the Xing header follows 32 bytes of side info
+	frame := make([]byte, 417)
+	copy(frame, []byte{0xff, 0xfb, 0x90, 0x00})
+	copy(frame[36:], "Xing")
+	binary.BigEndian.PutUint32(frame[40:], 0x1)
+	binary.BigEndian.PutUint32(frame[44:], 3828) // 3828 frames * 1152 samples / 44100 Hz = 100s
+
+	got, err := EstimateMp3Duration(frame)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Round(time.Millisecond) != 99997*time.Millisecond {
+		t.Errorf("EstimateMp3Duration() = %s, want ~100s", got)
+	}
+}
+
+func TestEstimateMp3DurationRejectsNonMp3(t *testing.T) {
+	if _, err := EstimateMp3Duration([]byte("not audio at all")); err == nil {
+		t.Fatal("expected an error")
+	}
+}
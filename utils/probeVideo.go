@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+var ffmpegDurationPattern = regexp.MustCompile(`Duration: (\d+):(\d{2}):(\d{2}(?:\.\d+)?)`)
+
+// ProbeDuration returns the length of a media file as reported by ffmpeg.
+func ProbeDuration(path string) (time.Duration, error) {
+	ffmpegPath, err := ResolveFFmpegPath()
+	if err != nil {
+		return 0, err
+	}
+	// ffmpeg exits non-zero when no output is given, but still prints the
+	// input's metadata to stderr, so the error is intentionally ignored.
+	output, _ := exec.Command(ffmpegPath, "-hide_banner", "-i", path).CombinedOutput()
+	return parseFFmpegDuration(string(output))
+}
+
+// ExtractThumbnail writes a single JPEG frame taken at offset into the video to thumbnailPath.
+func ExtractThumbnail(videoPath string, thumbnailPath string, offset time.Duration) error {
+	ffmpegPath, err := ResolveFFmpegPath()
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(ffmpegPath,
+		"-y",
+		"-ss", fmt.Sprintf("%.3f", offset.Seconds()),
+		"-i", videoPath,
+		"-frames:v", "1",
+		"-vf", "scale=640:-2",
+		thumbnailPath,
+	)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to extract thumbnail: %v: %s", err, output)
+	}
+	return nil
+}
+
+// parseFFmpegDuration extracts the "Duration: HH:MM:SS.ss" line from ffmpeg's input summary.
+func parseFFmpegDuration(output string) (time.Duration, error) {
+	matches := ffmpegDurationPattern.FindStringSubmatch(output)
+	if len(matches) != 4 {
+		return 0, fmt.Errorf("no duration found in ffmpeg output")
+	}
+	hours, _ := strconv.Atoi(matches[1])
+	minutes, _ := strconv.Atoi(matches[2])
+	seconds, _ := strconv.ParseFloat(matches[3], 64)
+	return time.Duration(hours)*time.Hour +
+		time.Duration(minutes)*time.Minute +
+		time.Duration(seconds*float64(time.Second)), nil
+}
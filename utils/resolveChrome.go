@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/codevideo/codevideo-cli/constants"
+)
+
+// ResolveNodePath returns the node executable used to run the Puppeteer runner.
+func ResolveNodePath() (string, error) {
+	nodePath, err := exec.LookPath("node")
+	if err != nil {
+		return "", fmt.Errorf("node is required: install Node.js 18 or newer and put it on PATH")
+	}
+	return nodePath, nil
+}
+
+// ResolveChromePath returns the Chrome executable the Puppeteer runner would
+// launch. It asks the runner's own runtimePaths.js so both agree on the
+// lookup order (CODEVIDEO_CHROME_PATH, the browser cache, system installs, PATH).
+func ResolveChromePath(ctx context.Context) (string, error) {
+	nodePath, err := ResolveNodePath()
+	if err != nil {
+		return "", err
+	}
+	runtimePaths := filepath.Join(filepath.Dir(constants.PuppeteerRunnerPath()), "runtimePaths.js")
+	if _, err := os.Stat(runtimePaths); err != nil {
+		return "", fmt.Errorf("runner helper %s is missing: %w", runtimePaths, err)
+	}
+	script := fmt.Sprintf("process.stdout.write(require(%q).resolveChromeExecutable())", runtimePaths)
+	output, err := exec.CommandContext(ctx, nodePath, "-e", script).CombinedOutput()
+	if err != nil {
+		// the runner's error message explains how to install Chrome
+		message := strings.TrimSpace(string(output))
+		if i := strings.LastIndex(message, "Error: "); i >= 0 {
+			message = strings.SplitN(message[i+len("Error: "):], "\n", 2)[0]
+		}
+		return "", fmt.Errorf("chrome could not be resolved: %s", message)
+	}
+	return strings.TrimSpace(string(output)), nil
+}